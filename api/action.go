@@ -0,0 +1,120 @@
+package api
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Action is the body of an action request (e.g. starting a VM) and the result returned by the engine
+type Action struct {
+	XMLName xml.Name `xml:"action"`
+	ID      string   `xml:"id,attr,omitempty"`
+	Href    string   `xml:"href,attr,omitempty"`
+	Status  string   `xml:"status,omitempty"`
+	Reason  string   `xml:"reason,omitempty"`
+	Force   bool     `xml:"force,omitempty"`
+	Fault   *Fault   `xml:"fault,omitempty"`
+
+	// parameters of the start action of a VM
+	Pause        bool `xml:"pause,omitempty"`
+	UseCloudInit bool `xml:"use_cloud_init,omitempty"`
+	UseSysprep   bool `xml:"use_sysprep,omitempty"`
+	Volatile     bool `xml:"volatile,omitempty"`
+
+	// accepted is set if the engine answered with 202 Accepted
+	accepted bool
+}
+
+// InProgress returns true if the engine accepted the action but did not complete it yet.
+// The state of the action has to be polled using its href in this case.
+func (a *Action) InProgress() bool {
+	return a.accepted || a.Status == "pending" || a.Status == "in_progress"
+}
+
+// maxActionPollInterval is the maximum delay between two polls of an action
+const maxActionPollInterval = 10 * time.Second
+
+// WaitForAction polls the action at href (as returned by an asynchronous action) until it is
+// complete or failed. The fault of a failed action is returned as error.
+func (c *Client) WaitForAction(href string, timeout time.Duration) (*Action, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	a, err := c.WaitForActionContext(ctx, href)
+	if err != nil && ctx.Err() == context.DeadlineExceeded {
+		status := ""
+		if a != nil {
+			status = a.Status
+		}
+		return a, fmt.Errorf("action did not complete within %s (last status: %s): %w", timeout, status, err)
+	}
+
+	return a, err
+}
+
+// WaitForActionContext polls the action at href until it is complete or failed. The delay between
+// two polls grows from pollInterval up to 10 seconds. It returns ctx.Err() as soon as ctx is done.
+// The last action seen is returned along with any error.
+func (c *Client) WaitForActionContext(ctx context.Context, href string) (*Action, error) {
+	path := c.relativePath(href)
+	delay := pollInterval
+
+	var last *Action
+	for {
+		a := &Action{}
+		err := c.GetAndParseContext(ctx, path, a)
+		if ctx.Err() != nil {
+			return last, ctx.Err()
+		}
+		if err != nil {
+			return last, err
+		}
+		last = a
+
+		switch a.Status {
+		case "complete":
+			return a, nil
+		case "failed":
+			if a.Fault != nil {
+				return a, a.Fault
+			}
+			return a, fmt.Errorf("action %s failed", href)
+		}
+
+		err = sleepContext(ctx, delay)
+		if err != nil {
+			return last, err
+		}
+
+		delay *= 2
+		if delay > maxActionPollInterval {
+			delay = maxActionPollInterval
+		}
+	}
+}
+
+// performAction posts the action to the sub path of an entity (e.g. /vms/123/start)
+func (c *Client) performAction(path, name string, a *Action) (*Action, error) {
+	if a == nil {
+		a = &Action{}
+	}
+
+	resp, err := c.send(context.Background(), path+"/"+name, "POST", a, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &Action{}
+	if len(resp.Body) > 0 {
+		err = xml.Unmarshal(resp.Body, res)
+		if err != nil {
+			return nil, err
+		}
+	}
+	res.accepted = resp.StatusCode == http.StatusAccepted
+
+	return res, nil
+}
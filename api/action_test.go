@@ -0,0 +1,124 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestWaitForActionContextCancelled(t *testing.T) {
+	e := newTestEngine(t)
+	e.handle("/vms/123/start/456", func(w http.ResponseWriter, r *http.Request) {
+		writeXML(w, http.StatusOK, `<action id="456"><status>pending</status></action>`)
+	})
+	c := e.client(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+	a, err := c.WaitForActionContext(ctx, testAPIPath+"/vms/123/start/456")
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+
+	if d := time.Since(start); d > time.Second {
+		t.Fatalf("WaitForActionContext returned after %s instead of when the context was cancelled", d)
+	}
+
+	if a == nil || a.Status != "pending" {
+		t.Fatalf("expected the last action seen, got %+v", a)
+	}
+}
+
+func TestWaitForActionTimeout(t *testing.T) {
+	e := newTestEngine(t)
+	e.handle("/vms/123/start/456", func(w http.ResponseWriter, r *http.Request) {
+		writeXML(w, http.StatusOK, `<action id="456"><status>in_progress</status></action>`)
+	})
+	c := e.client(t)
+
+	start := time.Now()
+	_, err := c.WaitForAction(testAPIPath+"/vms/123/start/456", 100*time.Millisecond)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+
+	if d := time.Since(start); d > time.Second {
+		t.Fatalf("WaitForAction returned after %s instead of after the timeout", d)
+	}
+}
+
+func TestActionAccepted(t *testing.T) {
+	e := newTestEngine(t)
+	e.handle("/vms/123/start", func(w http.ResponseWriter, r *http.Request) {
+		writeXML(w, http.StatusAccepted, `<action id="456" href="/ovirt-engine/api/vms/123/start/456"><status>pending</status></action>`)
+	})
+	e.handle("/vms/123/stop", func(w http.ResponseWriter, r *http.Request) {
+		writeXML(w, http.StatusOK, `<action><status>complete</status></action>`)
+	})
+	c := e.client(t)
+
+	a, err := c.StartVM("123")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !a.InProgress() {
+		t.Fatal("action answered with 202 is not in progress")
+	}
+
+	if a.Href != "/ovirt-engine/api/vms/123/start/456" {
+		t.Fatalf("unexpected href %q", a.Href)
+	}
+
+	a, err = c.StopVM("123")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if a.InProgress() {
+		t.Fatal("completed action is in progress")
+	}
+}
+
+func TestWaitForAction(t *testing.T) {
+	setPollInterval(t, time.Millisecond)
+
+	e := newTestEngine(t)
+	states := []string{"pending", "in_progress", "complete"}
+	polls := 0
+	e.handle("/vms/123/start/456", func(w http.ResponseWriter, r *http.Request) {
+		s := states[polls]
+		if polls < len(states)-1 {
+			polls++
+		}
+		writeXML(w, http.StatusOK, `<action id="456"><status>`+s+`</status></action>`)
+	})
+	e.handle("/vms/123/stop/789", func(w http.ResponseWriter, r *http.Request) {
+		writeXML(w, http.StatusOK, `<action id="789"><status>failed</status><fault><reason>Operation Failed</reason><detail>VM is locked</detail></fault></action>`)
+	})
+	c := e.client(t)
+
+	a, err := c.WaitForAction(testAPIPath+"/vms/123/start/456", 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if a.Status != "complete" || polls != 2 {
+		t.Fatalf("expected the action to complete after 3 polls, got %s after %d", a.Status, polls+1)
+	}
+
+	a, err = c.WaitForAction(testAPIPath+"/vms/123/stop/789", 5*time.Second)
+
+	var f *Fault
+	if !errors.As(err, &f) || f.Detail != "VM is locked" {
+		t.Fatalf("expected the fault of the action, got %v", err)
+	}
+
+	if a == nil || a.Status != "failed" {
+		t.Fatalf("expected the failed action, got %+v", a)
+	}
+}
@@ -0,0 +1,15 @@
+package api
+
+import "io"
+
+// API is the set of generic request methods of the client, so consumers can be written
+// against an interface (e.g. to replace the client by a fake)
+type API interface {
+	Get(path string, opts ...RequestOption) ([]byte, error)
+	GetAndParse(path string, v interface{}, opts ...RequestOption) error
+	SendRequest(path, method string, body io.Reader, opts ...RequestOption) ([]byte, error)
+	SendAndParse(path, method string, res interface{}, body io.Reader, opts ...RequestOption) error
+	Close() error
+}
+
+var _ API = (*Client)(nil)
@@ -0,0 +1,145 @@
+package api
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// APIInfo is the entry point document of the API (/api)
+type APIInfo struct {
+	XMLName        xml.Name        `xml:"api"`
+	ProductInfo    *ProductInfo    `xml:"product_info"`
+	SpecialObjects *SpecialObjects `xml:"special_objects"`
+	Summary        *Summary        `xml:"summary"`
+}
+
+// ProductInfo describes the engine
+type ProductInfo struct {
+	Name    string      `xml:"name"`
+	Vendor  string      `xml:"vendor"`
+	Version *APIVersion `xml:"version"`
+}
+
+// APIVersion is the version of the engine
+type APIVersion struct {
+	Major       int    `xml:"major"`
+	Minor       int    `xml:"minor"`
+	Build       int    `xml:"build"`
+	Revision    int    `xml:"revision"`
+	FullVersion string `xml:"full_version"`
+}
+
+// AtLeast reports if the version is major.minor or newer
+func (v *APIVersion) AtLeast(major, minor int) bool {
+	if v.Major != major {
+		return v.Major > major
+	}
+
+	return v.Minor >= minor
+}
+
+// SpecialObjects references entities with a special meaning (e.g. the Blank template)
+type SpecialObjects struct {
+	BlankTemplate *Link `xml:"blank_template"`
+	RootTag       *Link `xml:"root_tag"`
+}
+
+// Summary contains the number of entities managed by the engine
+type Summary struct {
+	Hosts          *SummaryCount `xml:"hosts"`
+	StorageDomains *SummaryCount `xml:"storage_domains"`
+	Users          *SummaryCount `xml:"users"`
+	VMs            *SummaryCount `xml:"vms"`
+}
+
+// SummaryCount is the number of active and total entities of a type
+type SummaryCount struct {
+	Active int `xml:"active"`
+	Total  int `xml:"total"`
+}
+
+// apiInfoCache holds the entry point document. It is shared with clones of the client.
+type apiInfoCache struct {
+	mu   sync.Mutex
+	info *APIInfo
+}
+
+// Warmup authenticates and reads the entry point of the API (version, summary and special objects)
+// in one step and caches the result, so later operations do not have to look them up.
+// It is safe to call concurrently. The error lists every lookup that failed.
+func (c *Client) Warmup(ctx context.Context) error {
+	err := c.connect(ctx)
+	if err != nil {
+		return fmt.Errorf("warmup: authentication failed: %w", err)
+	}
+
+	info, err := c.apiInfo(ctx, true)
+	if err != nil {
+		return fmt.Errorf("warmup: reading api entry point failed: %w", err)
+	}
+
+	missing := []string{}
+	if info.ProductInfo == nil || info.ProductInfo.Version == nil {
+		missing = append(missing, "version")
+	}
+	if info.Summary == nil {
+		missing = append(missing, "summary")
+	}
+	if info.SpecialObjects == nil {
+		missing = append(missing, "special_objects")
+	}
+
+	if len(missing) > 0 {
+		return errors.New("warmup: api entry point did not contain " + strings.Join(missing, ", "))
+	}
+
+	return nil
+}
+
+// Version returns the version of the engine. It is read from the entry point of the API once
+// and cached afterwards (see Warmup).
+func (c *Client) Version() (*APIVersion, error) {
+	info, err := c.apiInfo(context.Background(), false)
+	if err != nil {
+		return nil, err
+	}
+
+	if info.ProductInfo == nil || info.ProductInfo.Version == nil {
+		return nil, errors.New("api entry point does not contain the product version")
+	}
+
+	return info.ProductInfo.Version, nil
+}
+
+// apiInfo returns the cached entry point document and retrieves it if not cached yet or refresh is set
+func (c *Client) apiInfo(ctx context.Context, refresh bool) (*APIInfo, error) {
+	cache := c.apiInfoCache
+	if cache == nil {
+		cache = &apiInfoCache{}
+	}
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	if cache.info != nil && !refresh {
+		return cache.info, nil
+	}
+
+	resp, err := c.sendRequest(ctx, "/", "GET", nil, true, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &APIInfo{}
+	err = xml.Unmarshal(resp.Body, info)
+	if err != nil {
+		return nil, err
+	}
+
+	cache.info = info
+	return info, nil
+}
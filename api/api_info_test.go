@@ -0,0 +1,132 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+)
+
+// apiDocument is the entry point of the API as returned by an engine 4.4
+const apiDocument = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<api>
+  <link href="/ovirt-engine/api/clusters" rel="clusters"/>
+  <link href="/ovirt-engine/api/vms" rel="vms"/>
+  <link href="/ovirt-engine/api/vms?search={query}" rel="vms/search"/>
+  <product_info>
+    <instance_id>8d1f1a1e-3b7c-11eb-b0a4-00163e5a1b2c</instance_id>
+    <name>oVirt Engine</name>
+    <vendor>ovirt.org</vendor>
+    <version>
+      <build>6</build>
+      <full_version>4.4.10.6-1.el8</full_version>
+      <major>4</major>
+      <minor>4</minor>
+      <revision>0</revision>
+    </version>
+  </product_info>
+  <special_objects>
+    <blank_template href="/ovirt-engine/api/templates/00000000-0000-0000-0000-000000000000" id="00000000-0000-0000-0000-000000000000"/>
+    <root_tag href="/ovirt-engine/api/tags/00000000-0000-0000-0000-000000000000" id="00000000-0000-0000-0000-000000000000"/>
+  </special_objects>
+  <summary>
+    <hosts>
+      <active>2</active>
+      <total>3</total>
+    </hosts>
+    <storage_domains>
+      <active>4</active>
+      <total>4</total>
+    </storage_domains>
+    <users>
+      <active>1</active>
+      <total>5</total>
+    </users>
+    <vms>
+      <active>7</active>
+      <total>12</total>
+    </vms>
+  </summary>
+  <time>2021-03-04T10:15:30.123+01:00</time>
+  <authenticated_user href="/ovirt-engine/api/users/0000-0001" id="0000-0001"/>
+  <effective_user href="/ovirt-engine/api/users/0000-0001" id="0000-0001"/>
+</api>`
+
+// handleAPIDocument serves apiDocument at the entry point and returns the number of requests
+func (e *testEngine) handleAPIDocument() *int32 {
+	var n int32
+	e.mux.HandleFunc(testAPIPath+"/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != testAPIPath+"/" {
+			http.NotFound(w, r)
+			return
+		}
+
+		atomic.AddInt32(&n, 1)
+		writeXML(w, http.StatusOK, apiDocument)
+	})
+
+	return &n
+}
+
+func TestVersion(t *testing.T) {
+	e := newTestEngine(t)
+	requests := e.handleAPIDocument()
+	c := e.client(t)
+
+	v, err := c.Version()
+	if err != nil {
+		t.Fatalf("Version: %v", err)
+	}
+	want := APIVersion{Major: 4, Minor: 4, Build: 6, Revision: 0, FullVersion: "4.4.10.6-1.el8"}
+	if *v != want {
+		t.Fatalf("expected %+v, got %+v", want, *v)
+	}
+
+	_, err = c.Version()
+	if err != nil {
+		t.Fatalf("Version: %v", err)
+	}
+	if n := atomic.LoadInt32(requests); n != 1 {
+		t.Fatalf("expected the entry point to be read once, got %d requests", n)
+	}
+}
+
+func TestWarmup(t *testing.T) {
+	e := newTestEngine(t)
+	e.handleAPIDocument()
+	c := e.client(t)
+
+	err := c.Warmup(context.Background())
+	if err != nil {
+		t.Fatalf("Warmup: %v", err)
+	}
+
+	info, err := c.apiInfo(context.Background(), false)
+	if err != nil {
+		t.Fatalf("apiInfo: %v", err)
+	}
+	if info.Summary.VMs.Total != 12 || info.Summary.Hosts.Active != 2 || info.SpecialObjects.BlankTemplate.ID != "00000000-0000-0000-0000-000000000000" {
+		t.Fatalf("unexpected entry point %+v", info)
+	}
+}
+
+func TestAtLeast(t *testing.T) {
+	v := &APIVersion{Major: 4, Minor: 4}
+
+	tests := []struct {
+		major, minor int
+		want         bool
+	}{
+		{3, 6, true},
+		{4, 0, true},
+		{4, 3, true},
+		{4, 4, true},
+		{4, 5, false},
+		{5, 0, false},
+	}
+	for _, tc := range tests {
+		if got := v.AtLeast(tc.major, tc.minor); got != tc.want {
+			t.Errorf("AtLeast(%d, %d) of 4.4: expected %t, got %t", tc.major, tc.minor, tc.want, got)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// listVMNames is written against the interface only
+func listVMNames(a API) ([]string, error) {
+	vms := &VMs{}
+	err := a.GetAndParse("/vms", vms)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(vms.VMs))
+	for _, vm := range vms.VMs {
+		names = append(names, vm.Name)
+	}
+
+	return names, nil
+}
+
+func TestAPI(t *testing.T) {
+	e := newTestEngine(t)
+	e.handle("/vms", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "POST" {
+			writeXML(w, http.StatusCreated, `<vm id="3"><name>created</name></vm>`)
+			return
+		}
+		writeXML(w, http.StatusOK, vmsListing)
+	})
+	e.mux.HandleFunc("/ovirt-engine/sso/oauth/revoke", func(w http.ResponseWriter, r *http.Request) {})
+
+	var a API = e.client(t)
+
+	names, err := listVMNames(a)
+	if err != nil {
+		t.Fatalf("GetAndParse: %v", err)
+	}
+	if strings.Join(names, ",") != "web01,db01" {
+		t.Fatalf("unexpected names %v", names)
+	}
+
+	b, err := a.Get("/vms")
+	if err != nil || !strings.Contains(string(b), "<vms>") {
+		t.Fatalf("Get: %q, %v", b, err)
+	}
+
+	b, err = a.SendRequest("/vms", "POST", strings.NewReader(`<vm><name>created</name></vm>`))
+	if err != nil || !strings.Contains(string(b), `id="3"`) {
+		t.Fatalf("SendRequest: %q, %v", b, err)
+	}
+
+	vm := &VM{}
+	err = a.SendAndParse("/vms", "POST", vm, strings.NewReader(`<vm><name>created</name></vm>`))
+	if err != nil || vm.ID != "3" {
+		t.Fatalf("SendAndParse: %+v, %v", vm, err)
+	}
+
+	err = a.Close()
+	if err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+}
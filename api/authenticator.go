@@ -0,0 +1,68 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"sync"
+)
+
+// Authenticator authorizes the requests of a client, e.g. to use Kerberos (SPNEGO) instead of
+// SSO tokens. It replaces the built-in token handling when set with WithAuthenticator.
+type Authenticator interface {
+	// Authorize adds the credentials to a request before it is sent
+	Authorize(req *http.Request) error
+
+	// Refresh renews the credentials after the engine rejected a request
+	Refresh() error
+}
+
+// WithAuthenticator authorizes all requests with a instead of the SSO token obtained with the
+// username and password (or the credential provider) of the client.
+// A request rejected with 401 is sent again once after a.Refresh succeeded.
+func WithAuthenticator(a Authenticator) ClientOption {
+	return func(c *Client) {
+		c.authenticator = a
+	}
+}
+
+// PasswordAuth is an Authenticator sending SSO tokens requested with username and password,
+// the same way the client does by default
+type PasswordAuth struct {
+	credentials CredentialProvider
+
+	mu    sync.Mutex
+	token string
+}
+
+// NewPasswordAuth returns an authenticator for the engine at apiURL.
+// httpClient may be nil to use http.DefaultClient.
+func NewPasswordAuth(apiURL, username, password string, httpClient *http.Client) *PasswordAuth {
+	return &PasswordAuth{
+		credentials: NewPasswordCredentialProvider(apiURL, username, password, httpClient),
+	}
+}
+
+// Authorize sets the bearer token, requesting one if there is none yet
+func (a *PasswordAuth) Authorize(req *http.Request) error {
+	token, err := a.credentials.Token(req.Context())
+	if err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	a.token = token
+	a.mu.Unlock()
+
+	req.Header.Set("Authorization", "Bearer "+token)
+	return nil
+}
+
+// Refresh requests a new token unless the token last sent was already replaced
+func (a *PasswordAuth) Refresh() error {
+	a.mu.Lock()
+	token := a.token
+	a.mu.Unlock()
+
+	_, err := a.credentials.Refresh(context.Background(), token)
+	return err
+}
@@ -0,0 +1,101 @@
+package api
+
+import (
+	"net/http"
+	"sync/atomic"
+	"testing"
+)
+
+// stubAuth authorizes requests with a fixed header and counts the calls
+type stubAuth struct {
+	authorized int32
+	refreshed  int32
+}
+
+func (a *stubAuth) Authorize(req *http.Request) error {
+	atomic.AddInt32(&a.authorized, 1)
+	req.Header.Set("Authorization", "Negotiate stub")
+	return nil
+}
+
+func (a *stubAuth) Refresh() error {
+	atomic.AddInt32(&a.refreshed, 1)
+	return nil
+}
+
+func TestWithAuthenticator(t *testing.T) {
+	e := newTestEngine(t)
+	var rejected int32
+	e.handle("/vms", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Negotiate stub" {
+			t.Errorf("unexpected Authorization header %q", r.Header.Get("Authorization"))
+		}
+		if r.URL.Query().Get("reject") != "" && atomic.AddInt32(&rejected, 1) == 1 {
+			writeXML(w, http.StatusUnauthorized, "")
+			return
+		}
+		writeXML(w, http.StatusOK, "<vms/>")
+	})
+
+	a := &stubAuth{}
+	c := e.client(t, WithAuthenticator(a))
+	before := atomic.LoadInt32(&a.refreshed)
+
+	for i := 0; i < 3; i++ {
+		_, err := c.Get("/vms")
+		if err != nil {
+			t.Fatalf("Get: %v", err)
+		}
+	}
+	if n := atomic.LoadInt32(&a.authorized); n != 3 {
+		t.Fatalf("expected Authorize to be called for each of the 3 requests, got %d calls", n)
+	}
+
+	_, err := c.Get("/vms?reject=1")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if n := atomic.LoadInt32(&a.authorized); n != 5 {
+		t.Fatalf("expected the rejected request to be authorized again, got %d calls", n)
+	}
+	if n := atomic.LoadInt32(&a.refreshed) - before; n != 1 {
+		t.Fatalf("expected a single Refresh after the 401, got %d", n)
+	}
+	if n := e.tokens(); n != 0 {
+		t.Fatalf("expected no SSO token requests, got %d", n)
+	}
+}
+
+func TestPasswordAuth(t *testing.T) {
+	e := newTestEngine(t)
+	a := NewPasswordAuth(e.apiURL(), "user", "secret", nil)
+
+	req, _ := http.NewRequest("GET", e.apiURL(), nil)
+	err := a.Authorize(req)
+	if err != nil {
+		t.Fatalf("Authorize: %v", err)
+	}
+	if got := req.Header.Get("Authorization"); got != "Bearer token-1" {
+		t.Fatalf("expected the first token, got %q", got)
+	}
+
+	form := e.lastTokenForm()
+	if form.Get("grant_type") != "password" || form.Get("username") != "user" || form.Get("password") != "secret" {
+		t.Fatalf("unexpected token request %v", form)
+	}
+
+	err = a.Authorize(req)
+	if err != nil || req.Header.Get("Authorization") != "Bearer token-1" || e.tokens() != 1 {
+		t.Fatalf("expected the token to be reused, got %q after %d token requests (%v)",
+			req.Header.Get("Authorization"), e.tokens(), err)
+	}
+
+	err = a.Refresh()
+	if err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	err = a.Authorize(req)
+	if err != nil || req.Header.Get("Authorization") != "Bearer token-2" {
+		t.Fatalf("expected the refreshed token, got %q (%v)", req.Header.Get("Authorization"), err)
+	}
+}
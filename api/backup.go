@@ -0,0 +1,91 @@
+package api
+
+import (
+	"encoding/xml"
+	"errors"
+)
+
+// BackupPhase is the phase of a VM backup
+type BackupPhase string
+
+const (
+	// BackupInitializing means the backup is being prepared
+	BackupInitializing BackupPhase = "initializing"
+	// BackupStarting means the engine is starting the backup on the host
+	BackupStarting BackupPhase = "starting"
+	// BackupReady means the disks can be downloaded
+	BackupReady BackupPhase = "ready"
+	// BackupFinalizing means the backup is being finalized
+	BackupFinalizing BackupPhase = "finalizing"
+	// BackupSucceeded means the backup finished and its checkpoint can be used for the next incremental backup
+	BackupSucceeded BackupPhase = "succeeded"
+	// BackupFailed means the backup failed
+	BackupFailed BackupPhase = "failed"
+)
+
+// Backups is a collection of VM backups as returned by the API
+type Backups struct {
+	Backups []Backup `xml:"backup"`
+}
+
+// Backup is a full or incremental (changed block tracking) backup of VM disks
+type Backup struct {
+	XMLName          xml.Name    `xml:"backup"`
+	ID               string      `xml:"id,attr,omitempty"`
+	Href             string      `xml:"href,attr,omitempty"`
+	Phase            BackupPhase `xml:"phase,omitempty"`
+	FromCheckpointID string      `xml:"from_checkpoint_id,omitempty"`
+	ToCheckpointID   string      `xml:"to_checkpoint_id,omitempty"`
+	Disks            []Link      `xml:"disks>disk,omitempty"`
+	Host             *Link       `xml:"host,omitempty"`
+}
+
+// StartVMBackup starts a backup of the disks of a VM. If fromCheckpointID is empty a full backup
+// is taken, otherwise only the blocks changed since the checkpoint. The ToCheckpointID of the
+// returned backup is the checkpoint to pass for the next incremental backup.
+func (c *Client) StartVMBackup(vmID, fromCheckpointID string, diskIDs ...string) (*Backup, error) {
+	if len(diskIDs) == 0 {
+		return nil, errors.New("at least one disk is required for a backup")
+	}
+
+	b := &Backup{FromCheckpointID: fromCheckpointID}
+	for _, id := range diskIDs {
+		b.Disks = append(b.Disks, Link{ID: id})
+	}
+
+	res := &Backup{}
+	err := c.SendObject("/vms/"+vmID+"/backups", "POST", b, res)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// ListBackups retrieves the backups of a VM
+func (c *Client) ListBackups(vmID string) ([]Backup, error) {
+	res := &Backups{}
+	err := c.GetAndParse("/vms/"+vmID+"/backups", res)
+	if err != nil {
+		return nil, err
+	}
+
+	return res.Backups, nil
+}
+
+// GetBackup retrieves a backup of a VM
+func (c *Client) GetBackup(vmID, backupID string) (*Backup, error) {
+	res := &Backup{}
+	err := c.GetAndParse("/vms/"+vmID+"/backups/"+backupID, res)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// FinalizeBackup finishes a backup after its disks have been downloaded
+func (c *Client) FinalizeBackup(vmID, backupID string) error {
+	_, err := c.performAction("/vms/"+vmID+"/backups/"+backupID, "finalize", nil)
+	return err
+}
@@ -0,0 +1,85 @@
+package api
+
+import "fmt"
+
+// BiosType combines the chipset and firmware of a VM
+type BiosType string
+
+const (
+	// BiosClusterDefault uses the default of the cluster
+	BiosClusterDefault BiosType = "cluster_default"
+	// BiosI440fxSeaBios is the i440fx chipset with legacy BIOS
+	BiosI440fxSeaBios BiosType = "i440fx_sea_bios"
+	// BiosQ35SeaBios is the q35 chipset with legacy BIOS
+	BiosQ35SeaBios BiosType = "q35_sea_bios"
+	// BiosQ35OVMF is the q35 chipset with UEFI
+	BiosQ35OVMF BiosType = "q35_ovmf"
+	// BiosQ35SecureBoot is the q35 chipset with UEFI and secure boot
+	BiosQ35SecureBoot BiosType = "q35_secure_boot"
+)
+
+// Chipset is the emulated chipset of a VM
+type Chipset string
+
+// Firmware is the firmware of a VM
+type Firmware string
+
+const (
+	// ChipsetI440fx is the legacy i440fx chipset
+	ChipsetI440fx Chipset = "i440fx"
+	// ChipsetQ35 is the q35 chipset
+	ChipsetQ35 Chipset = "q35"
+
+	// FirmwareSeaBios is the legacy BIOS
+	FirmwareSeaBios Firmware = "sea_bios"
+	// FirmwareOVMF is UEFI
+	FirmwareOVMF Firmware = "ovmf"
+	// FirmwareSecureBoot is UEFI with secure boot
+	FirmwareSecureBoot Firmware = "secure_boot"
+)
+
+// NewBiosType returns the bios type combining chipset and firmware.
+// UEFI and secure boot are only available with the q35 chipset.
+func NewBiosType(chipset Chipset, firmware Firmware) (BiosType, error) {
+	switch chipset {
+	case ChipsetI440fx, ChipsetQ35:
+	default:
+		return "", fmt.Errorf("unsupported chipset %q", chipset)
+	}
+
+	switch firmware {
+	case FirmwareSeaBios:
+	case FirmwareOVMF, FirmwareSecureBoot:
+		if chipset != ChipsetQ35 {
+			return "", fmt.Errorf("firmware %s requires the q35 chipset", firmware)
+		}
+	default:
+		return "", fmt.Errorf("unsupported firmware %q", firmware)
+	}
+
+	return BiosType(string(chipset) + "_" + string(firmware)), nil
+}
+
+// Bios describes the firmware of a VM
+type Bios struct {
+	Type     BiosType  `xml:"type,omitempty"`
+	BootMenu *BootMenu `xml:"boot_menu,omitempty"`
+}
+
+// BootMenu enables the interactive boot menu of the firmware
+type BootMenu struct {
+	Enabled bool `xml:"enabled"`
+}
+
+// Validate checks the bios type is one of the supported chipset/firmware combinations
+// (see NewBiosType)
+func (b *Bios) Validate() error {
+	switch b.Type {
+	case "", BiosClusterDefault, BiosI440fxSeaBios, BiosQ35SeaBios, BiosQ35OVMF, BiosQ35SecureBoot:
+		return nil
+	case "i440fx_ovmf", "i440fx_secure_boot":
+		return fmt.Errorf("bios type %s is not supported, UEFI requires the q35 chipset", b.Type)
+	default:
+		return fmt.Errorf("unsupported bios type %q", b.Type)
+	}
+}
@@ -0,0 +1,67 @@
+package api
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewBiosType(t *testing.T) {
+	tests := []struct {
+		chipset  Chipset
+		firmware Firmware
+		want     BiosType
+		err      string
+	}{
+		{ChipsetI440fx, FirmwareSeaBios, BiosI440fxSeaBios, ""},
+		{ChipsetQ35, FirmwareSeaBios, BiosQ35SeaBios, ""},
+		{ChipsetQ35, FirmwareOVMF, BiosQ35OVMF, ""},
+		{ChipsetQ35, FirmwareSecureBoot, BiosQ35SecureBoot, ""},
+		{ChipsetI440fx, FirmwareOVMF, "", "requires the q35 chipset"},
+		{ChipsetI440fx, FirmwareSecureBoot, "", "requires the q35 chipset"},
+		{"pc", FirmwareSeaBios, "", "unsupported chipset"},
+		{ChipsetQ35, "efi", "", "unsupported firmware"},
+	}
+
+	for _, tc := range tests {
+		got, err := NewBiosType(tc.chipset, tc.firmware)
+		if tc.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Errorf("%s/%s: expected error containing %q, got %v", tc.chipset, tc.firmware, tc.err, err)
+			}
+			continue
+		}
+
+		if err != nil || got != tc.want {
+			t.Errorf("%s/%s: expected %s, got %s (%v)", tc.chipset, tc.firmware, tc.want, got, err)
+		}
+
+		b := &Bios{Type: got}
+		if err := b.Validate(); err != nil {
+			t.Errorf("%s: %v", got, err)
+		}
+	}
+}
+
+func TestBiosValidate(t *testing.T) {
+	tests := map[BiosType]string{
+		"":                 "",
+		BiosClusterDefault: "",
+		BiosQ35SecureBoot:  "",
+		"i440fx_ovmf":      "UEFI requires the q35 chipset",
+		"q35_ovfm":         `unsupported bios type "q35_ovfm"`,
+	}
+
+	for typ, msg := range tests {
+		err := (&Bios{Type: typ}).Validate()
+		if msg == "" {
+			if err != nil {
+				t.Errorf("%q: unexpected error %v", typ, err)
+			}
+			continue
+		}
+
+		if err == nil || !strings.Contains(err.Error(), msg) {
+			t.Errorf("%q: expected error containing %q, got %v", typ, msg, err)
+		}
+	}
+}
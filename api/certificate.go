@@ -0,0 +1,255 @@
+package api
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// vdsmPort is the port the VDSM daemon of a host listens on
+const vdsmPort = "54321"
+
+// CertificateStatus describes the validity of a certificate used in the environment
+type CertificateStatus struct {
+	// Entity is "engine-ca", "engine" or "host:<name>"
+	Entity   string
+	Subject  string
+	NotAfter time.Time
+
+	// Err is set if the certificate could not be retrieved
+	Err error
+}
+
+// ExpiresWithin returns true if the certificate expires within d
+func (s *CertificateStatus) ExpiresWithin(d time.Duration) bool {
+	return s.Err == nil && time.Until(s.NotAfter) < d
+}
+
+// CertificateReport lists the certificates of the engine and its hosts
+type CertificateReport struct {
+	Certificates []CertificateStatus
+
+	// Warnings are the certificate expiry events of the engine not acknowledged yet
+	Warnings []Event
+}
+
+// Expiring returns the certificates expiring within d
+func (r *CertificateReport) Expiring(d time.Duration) []CertificateStatus {
+	res := []CertificateStatus{}
+	for _, s := range r.Certificates {
+		if s.ExpiresWithin(d) {
+			res = append(res, s)
+		}
+	}
+
+	return res
+}
+
+const (
+	// maxCertificateInspections is the number of hosts inspected at a time
+	maxCertificateInspections = 8
+	// certificateInspectTimeout limits the handshake with a single host
+	certificateInspectTimeout = 10 * time.Second
+)
+
+// CertificateExpiry reports the expiry dates of the engine CA, the engine and all host certificates.
+// The API does not expose the expiry dates, so the CA certificate is fetched from the PKI resource
+// of the engine and the others are read from the TLS handshakes with the engine and the hosts' VDSM.
+// Certificates which could not be retrieved are reported with Err set.
+func (c *Client) CertificateExpiry() (*CertificateReport, error) {
+	return c.CertificateExpiryContext(context.Background())
+}
+
+// CertificateExpiryContext reports the expiry dates of the certificates and the expiry warnings of
+// the engine. The engine certificate is read from the response of the CA request, so both use the
+// transport, proxy and TLS options of the client. The hosts are inspected concurrently with the dialer
+// and the TLS options of the client, but without verifying their certificates (which may have expired).
+func (c *Client) CertificateExpiryContext(ctx context.Context) (*CertificateReport, error) {
+	u, err := url.Parse(c.url)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &CertificateReport{}
+
+	ca, state, err := c.fetchCACertificate(ctx)
+	report.add("engine-ca", ca, err)
+
+	switch {
+	case u.Scheme != "https":
+		report.add("engine", nil, errors.New("engine is not accessed via https"))
+	case state != nil && len(state.PeerCertificates) > 0:
+		report.add("engine", state.PeerCertificates[0], nil)
+	default:
+		// the CA request failed before the handshake completed
+		report.add("engine", nil, err)
+	}
+
+	hosts := &Hosts{}
+	err = c.GetAndParseContext(ctx, "/hosts", hosts)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]CertificateStatus, len(hosts.Hosts))
+	sem := make(chan struct{}, maxCertificateInspections)
+	wg := sync.WaitGroup{}
+
+	for i, h := range hosts.Hosts {
+		wg.Add(1)
+		sem <- struct{}{}
+
+		go func(i int, h Host) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			cert, err := c.inspectTLSCertificate(ctx, net.JoinHostPort(h.Address, vdsmPort))
+			statuses[i] = newCertificateStatus("host:"+h.Name, cert, err)
+		}(i, h)
+	}
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	report.Certificates = append(report.Certificates, statuses...)
+
+	report.Warnings, err = c.certificateWarnings(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// AcknowledgeCertificateWarning dismisses a certificate expiry event (see CertificateReport.Warnings),
+// so it is no longer reported
+func (c *Client) AcknowledgeCertificateWarning(eventID string) error {
+	_, err := c.Delete("/events/" + eventID)
+	return err
+}
+
+// certificateWarnings returns the events of the engine warning about expiring or expired certificates
+func (c *Client) certificateWarnings(ctx context.Context) ([]Event, error) {
+	events, err := c.listEvents(ctx, "message=*certificat*", 0, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	res := []Event{}
+	for _, e := range events {
+		if strings.Contains(strings.ToLower(e.Description), "expire") {
+			res = append(res, e)
+		}
+	}
+
+	return res, nil
+}
+
+func (r *CertificateReport) add(entity string, cert *x509.Certificate, err error) {
+	r.Certificates = append(r.Certificates, newCertificateStatus(entity, cert, err))
+}
+
+func newCertificateStatus(entity string, cert *x509.Certificate, err error) CertificateStatus {
+	s := CertificateStatus{Entity: entity, Err: err}
+	if cert != nil {
+		s.Subject = cert.Subject.String()
+		s.NotAfter = cert.NotAfter
+	}
+
+	return s
+}
+
+// FetchCACertificate retrieves the CA certificate of the engine
+func (c *Client) FetchCACertificate() (*x509.Certificate, error) {
+	cert, _, err := c.fetchCACertificate(context.Background())
+	return cert, err
+}
+
+// fetchCACertificate retrieves the CA certificate of the engine along with the TLS connection state
+// of the response (nil if no response was received or the engine is not accessed via https)
+func (c *Client) fetchCACertificate(ctx context.Context) (*x509.Certificate, *tls.ConnectionState, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", engineBaseURL(c.url)+"/services/pki-resource?resource=ca-certificate&format=X509-PEM-CA", nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, resp.TLS, errors.New(resp.Status)
+	}
+
+	b, err := readPayload(resp.Body)
+	if err != nil {
+		return nil, resp.TLS, err
+	}
+
+	block, _ := pem.Decode(b)
+	if block == nil {
+		return nil, resp.TLS, errors.New("no PEM encoded certificate found in CA response")
+	}
+
+	cert, err := x509.ParseCertificate(block.Bytes)
+	return cert, resp.TLS, err
+}
+
+// inspectTLSCertificate returns the leaf certificate presented by the server at addr. The connection
+// uses the dialer and the TLS settings (e.g. client certificates) of the transport of the client.
+// The certificate is not verified since it is only inspected.
+func (c *Client) inspectTLSCertificate(ctx context.Context, addr string) (*x509.Certificate, error) {
+	ctx, cancel := context.WithTimeout(ctx, certificateInspectTimeout)
+	defer cancel()
+
+	dial := (&net.Dialer{}).DialContext
+	cfg := &tls.Config{}
+	if tr, ok := c.client.Transport.(*http.Transport); ok {
+		if tr.DialContext != nil {
+			dial = tr.DialContext
+		}
+		if tr.TLSClientConfig != nil {
+			cfg = tr.TLSClientConfig.Clone()
+		}
+	}
+	cfg.InsecureSkipVerify = true
+
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	cfg.ServerName = host
+
+	conn, err := dial(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	tc := tls.Client(conn, cfg)
+	err = tc.HandshakeContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	certs := tc.ConnectionState().PeerCertificates
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("%s presented no certificate", addr)
+	}
+
+	return certs[0], nil
+}
@@ -0,0 +1,228 @@
+package api
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// hostDialer dials the servers of fake hosts instead of the port of VDSM
+type hostDialer map[string]string
+
+func (d hostDialer) dial(ctx context.Context, network, addr string) (net.Conn, error) {
+	if a, ok := d[addr]; ok {
+		addr = a
+	}
+
+	return (&net.Dialer{}).DialContext(ctx, network, addr)
+}
+
+// handleHosts lists the hosts with the names and addresses given
+func (e *testEngine) handleHosts(hosts ...string) {
+	e.handle("/hosts", func(w http.ResponseWriter, r *http.Request) {
+		b := strings.Builder{}
+		b.WriteString("<hosts>")
+		for _, h := range hosts {
+			b.WriteString("<host><name>" + h + "</name><address>" + h + ".example.com</address></host>")
+		}
+		b.WriteString("</hosts>")
+		writeXML(w, http.StatusOK, b.String())
+	})
+}
+
+func TestCertificateExpiry(t *testing.T) {
+	ca := newTestCA(t)
+	e := newTLSTestEngine(t, ca.serverConfig(t))
+	e.mux.HandleFunc("/ovirt-engine/services/pki-resource", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(ca.pem)
+	})
+	e.handleHosts("host-a", "host-b")
+
+	var search string
+	e.handle("/events", func(w http.ResponseWriter, r *http.Request) {
+		search = r.URL.Query().Get("search")
+		writeXML(w, http.StatusOK, `<events>
+  <event id="2"><index>2</index><description>Engine's certification is about to expire at 2026-11-01.</description></event>
+  <event id="3"><index>3</index><description>Certificate of host-a was enrolled.</description></event>
+</events>`)
+	})
+
+	// host-a requires the client certificate configured on the client
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert)
+	host := httptest.NewUnstartedServer(http.NotFoundHandler())
+	host.Config.ErrorLog = log.New(io.Discard, "", 0)
+	host.TLS = ca.serverConfig(t)
+	host.TLS.ClientAuth = tls.RequireAndVerifyClientCert
+	host.TLS.ClientCAs = pool
+	host.StartTLS()
+	defer host.Close()
+
+	d := hostDialer{
+		"host-a.example.com:" + vdsmPort: host.Listener.Addr().String(),
+		"host-b.example.com:" + vdsmPort: strings.TrimPrefix(closedURL(t), "http://"),
+	}
+	c := e.client(t, WithHTTPClient(&http.Client{Transport: &http.Transport{DialContext: d.dial}}),
+		WithCACert(ca.pem), WithClientCert(ca.issue(t, false)))
+
+	report, err := c.CertificateExpiryContext(context.Background())
+	if err != nil {
+		t.Fatalf("CertificateExpiryContext: %v", err)
+	}
+
+	entities := []string{"engine-ca", "engine", "host:host-a", "host:host-b"}
+	if len(report.Certificates) != len(entities) {
+		t.Fatalf("expected %d certificates, got %+v", len(entities), report.Certificates)
+	}
+	for i, s := range report.Certificates {
+		if s.Entity != entities[i] {
+			t.Fatalf("expected certificate %d to be %s, got %s", i, entities[i], s.Entity)
+		}
+	}
+
+	for _, s := range report.Certificates[:3] {
+		if s.Err != nil {
+			t.Fatalf("%s: %v", s.Entity, s.Err)
+		}
+	}
+	if got := report.Certificates[0].Subject; got != "CN=test CA" {
+		t.Fatalf("expected the subject of the CA, got %q", got)
+	}
+	if got := report.Certificates[1].Subject; got != "CN=127.0.0.1" {
+		t.Fatalf("expected the subject of the engine certificate, got %q", got)
+	}
+	if report.Certificates[3].Err == nil {
+		t.Fatal("expected an error for the unreachable host")
+	}
+
+	if n := len(report.Expiring(2 * time.Hour)); n != 3 {
+		t.Fatalf("expected 3 certificates expiring within 2 hours, got %d", n)
+	}
+	if n := len(report.Expiring(time.Minute)); n != 0 {
+		t.Fatalf("expected no certificate expiring within a minute, got %d", n)
+	}
+
+	if search != "message=*certificat*" {
+		t.Fatalf("unexpected event search %q", search)
+	}
+	if len(report.Warnings) != 1 || report.Warnings[0].ID != "2" {
+		t.Fatalf("expected only the expiry event as warning, got %+v", report.Warnings)
+	}
+}
+
+func TestCertificateExpiryConcurrency(t *testing.T) {
+	e := newTestEngine(t)
+	e.mux.HandleFunc("/ovirt-engine/services/pki-resource", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	e.handle("/events", func(w http.ResponseWriter, r *http.Request) {
+		writeXML(w, http.StatusOK, "<events/>")
+	})
+
+	hosts := []string{}
+	for i := 0; i < 3*maxCertificateInspections; i++ {
+		hosts = append(hosts, "host-"+string(rune('a'+i)))
+	}
+	e.handleHosts(hosts...)
+
+	var inFlight, maxInFlight int32
+	errUnreachable := errors.New("unreachable")
+	dial := func(ctx context.Context, network, addr string) (net.Conn, error) {
+		if !strings.HasSuffix(addr, ":"+vdsmPort) {
+			return (&net.Dialer{}).DialContext(ctx, network, addr)
+		}
+
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			m := atomic.LoadInt32(&maxInFlight)
+			if n <= m || atomic.CompareAndSwapInt32(&maxInFlight, m, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+
+		return nil, errUnreachable
+	}
+	c := e.client(t, WithHTTPClient(&http.Client{Transport: &http.Transport{DialContext: dial}}))
+
+	report, err := c.CertificateExpiry()
+	if err != nil {
+		t.Fatalf("CertificateExpiry: %v", err)
+	}
+
+	if got := atomic.LoadInt32(&maxInFlight); got > maxCertificateInspections || got < 2 {
+		t.Fatalf("expected up to %d concurrent inspections, got %d", maxCertificateInspections, got)
+	}
+
+	if len(report.Certificates) != 2+len(hosts) {
+		t.Fatalf("expected %d certificates, got %d", 2+len(hosts), len(report.Certificates))
+	}
+	if report.Certificates[0].Err == nil {
+		t.Fatal("expected an error for the CA certificate")
+	}
+	if got := report.Certificates[1].Err; got == nil || !strings.Contains(got.Error(), "https") {
+		t.Fatalf("expected the engine to be reported as not accessed via https, got %v", got)
+	}
+	for i, h := range hosts {
+		s := report.Certificates[2+i]
+		if s.Entity != "host:"+h || !errors.Is(s.Err, errUnreachable) {
+			t.Fatalf("expected host:%s to be unreachable, got %s: %v", h, s.Entity, s.Err)
+		}
+	}
+}
+
+func TestCertificateExpiryContextCanceled(t *testing.T) {
+	e := newTestEngine(t)
+	e.mux.HandleFunc("/ovirt-engine/services/pki-resource", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	e.handleHosts("host-a")
+
+	dial := func(ctx context.Context, network, addr string) (net.Conn, error) {
+		if !strings.HasSuffix(addr, ":"+vdsmPort) {
+			return (&net.Dialer{}).DialContext(ctx, network, addr)
+		}
+
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	c := e.client(t, WithHTTPClient(&http.Client{Transport: &http.Transport{DialContext: dial}}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := c.CertificateExpiryContext(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the deadline to be exceeded, got %v", err)
+	}
+}
+
+func TestAcknowledgeCertificateWarning(t *testing.T) {
+	e := newTestEngine(t)
+	var method, path string
+	e.handle("/events/", func(w http.ResponseWriter, r *http.Request) {
+		method, path = r.Method, r.URL.Path
+		writeXML(w, http.StatusOK, "<action/>")
+	})
+	c := e.client(t)
+
+	err := c.AcknowledgeCertificateWarning("2")
+	if err != nil {
+		t.Fatalf("AcknowledgeCertificateWarning: %v", err)
+	}
+
+	if method != "DELETE" || path != testAPIPath+"/events/2" {
+		t.Fatalf("expected DELETE %s/events/2, got %s %s", testAPIPath, method, path)
+	}
+}
@@ -1,12 +1,14 @@
 package api
 
 import (
-	"crypto/tls"
+	"bytes"
+	"context"
 	"encoding/json"
 	"encoding/xml"
 	"net/http"
 	"net/url"
 	"strings"
+	"time"
 
 	"errors"
 	"fmt"
@@ -15,13 +17,58 @@ import (
 
 // Client encapsulates communication with the oVirt REST API
 type Client struct {
-	url         string
-	username    string
-	password    string
-	logger      Logger
-	debug       bool
-	accessToken string
-	client      *http.Client
+	url            string
+	username       string
+	password       string
+	logger         Logger
+	debug          bool
+	lazyAuth       bool
+	noAutoReauth   bool
+	filter         bool
+	token          string
+	scope          string
+	ssoURL         string
+	userAgent      string
+	refreshSkew    time.Duration
+	now            func() time.Time
+	sleep          func(ctx context.Context, d time.Duration) error
+	compressMin    int
+	expectContinue time.Duration
+	timeout        time.Duration
+	// transferRate is accessed atomically
+	transferRate int64
+	retry        *retryPolicy
+	credentials  CredentialProvider
+	// sharedTransport is set if the transport of client belongs to someone else (a client passed
+	// with WithHTTPClient or the original of a clone), so it has to be copied before it is modified
+	sharedTransport bool
+	// ownCredentials is set if credentials is the provider created by NewClient
+	ownCredentials bool
+	authenticator  Authenticator
+	resolveCache   *resolveCache
+	headers        http.Header
+	correlationID  func() string
+	requestLogger  func(RequestInfo)
+	observer       Observer
+	rateLimiter    *rateLimiter
+	apiInfoCache   *apiInfoCache
+	client         *http.Client
+
+	// optionErr is the first error of an option, returned by NewClient
+	optionErr error
+}
+
+// Response is a response of the API
+type Response struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+
+	// CorrelationID is the correlation id sent with the request (if any)
+	CorrelationID string
+
+	// ETag is the entity tag of the returned representation (if any), see UpdateIfMatch
+	ETag string
 }
 
 // ClientOption applies options to Client
@@ -29,18 +76,31 @@ type ClientOption func(*Client)
 
 // SSO server response json
 type ssoResponseJSON struct {
-	AccessToken  string `json:"access_token"`
-	SsoError     string `json:"error"`
-	SsoErrorCode string `json:"error_code"`
+	AccessToken  string      `json:"access_token"`
+	ExpiresIn    jsonSeconds `json:"expires_in"`
+	SsoError     string      `json:"error"`
+	SsoErrorCode string      `json:"error_code"`
 }
 
-// WithInsecure disables TLS certificate validation
+// WithHTTPClient sets the HTTP client used for all requests (e.g. to configure a proxy or timeouts).
+// The client is copied, so it is never modified: options modifying the transport (like WithInsecure)
+// apply to a copy of its transport if they are passed after it, otherwise the transport and its
+// connection pool are shared. WithHTTPClient replaces the changes of such options passed before it.
+func WithHTTPClient(client *http.Client) ClientOption {
+	return func(c *Client) {
+		cp := *client
+		c.client = &cp
+		c.sharedTransport = true
+	}
+}
+
+// WithInsecure disables TLS certificate validation on the transport of the HTTP client
 func WithInsecure() ClientOption {
 	return func(c *Client) {
-		tr := &http.Transport{
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		cfg := c.tlsConfig()
+		if cfg != nil {
+			cfg.InsecureSkipVerify = true
 		}
-		c.client = &http.Client{Transport: tr}
 	}
 }
 
@@ -51,28 +111,126 @@ func WithLogger(l Logger) ClientOption {
 	}
 }
 
-// WithDebug enables debug mode
+// WithDebug enables debug mode, which logs responses and the debug messages of the default logger
 func WithDebug() ClientOption {
 	return func(c *Client) {
 		c.debug = true
 	}
 }
 
-// NewClient returns a new client
+// WithFilter sends the "Filter: true" header required for users without admin permissions,
+// so the engine only returns the entities the user has permissions for
+func WithFilter() ClientOption {
+	return func(c *Client) {
+		c.filter = true
+	}
+}
+
+// WithLazyAuth defers authentication to the first request (or an explicit call of Connect),
+// so creating the client does not require the engine to be reachable. Concurrent first
+// requests share a single authentication.
+func WithLazyAuth() ClientOption {
+	return func(c *Client) {
+		c.lazyAuth = true
+	}
+}
+
+// WithSSOURL sets the full URL of the SSO token endpoint (e.g. https://engine/ovirt-engine/sso/oauth/token)
+// if it can not be derived from the API URL, e.g. because the engine is accessed through a proxy.
+// Tokens are revoked at the revoke endpoint next to it. Ignored if WithCredentialProvider is used.
+func WithSSOURL(fullURL string) ClientOption {
+	return func(c *Client) {
+		c.ssoURL = fullURL
+	}
+}
+
+// WithoutAutoReauth returns requests rejected with 401 to the caller (as APIError) instead of
+// reauthenticating and sending them again
+func WithoutAutoReauth() ClientOption {
+	return func(c *Client) {
+		c.noAutoReauth = true
+	}
+}
+
+// WithToken reuses an existing SSO token (e.g. cached between invocations), so NewClient
+// does not authenticate. username and password may be empty in this case, but then the client
+// can not reauthenticate once the token is rejected. Ignored if WithCredentialProvider is used.
+func WithToken(token string) ClientOption {
+	return func(c *Client) {
+		c.token = token
+	}
+}
+
+// defaultScope is the OAuth scope requested by default, granting access to the API
+const defaultScope = "ovirt-app-api"
+
+// WithScope overrides the OAuth scope requested from the SSO server (ovirt-app-api by default),
+// e.g. "ovirt-app-admin" or multiple scopes separated by spaces. Ignored if WithCredentialProvider is used.
+func WithScope(scope string) ClientOption {
+	return func(c *Client) {
+		c.scope = scope
+	}
+}
+
+// libraryVersion is the version of this package, sent in the default user agent
+const libraryVersion = "0.1.0"
+
+// defaultUserAgent identifies requests of this package in the access log of the engine
+const defaultUserAgent = "ovirt_api-go/" + libraryVersion
+
+// WithUserAgent sets the User-Agent header sent with all requests (ovirt_api-go/<version> by default)
+func WithUserAgent(ua string) ClientOption {
+	return func(c *Client) {
+		c.userAgent = ua
+	}
+}
+
+// WithClock replaces the clock used for token expiry, e.g. to test refreshes without waiting.
+// now must be safe for concurrent use. Retry deadlines are checked against the real time
+// as they come from the request context.
+func WithClock(now func() time.Time) ClientOption {
+	return func(c *Client) {
+		c.now = now
+	}
+}
+
+// NewClient returns a new client for the API at url (e.g. https://engine.example.com/ovirt-engine/api),
+// which has to use the http or https scheme
 func NewClient(url, username, password string, opts ...ClientOption) (*Client, error) {
+	url, err := normalizeBaseURL(url)
+	if err != nil {
+		return nil, err
+	}
+
 	client := &Client{
-		url:      url,
-		username: username,
-		password: password,
-		client:   &http.Client{},
-		logger:   &defaultLogger{},
+		url:       url,
+		username:  username,
+		password:  password,
+		scope:     defaultScope,
+		userAgent: defaultUserAgent,
+		client:    &http.Client{},
+		logger:    &defaultLogger{},
+
+		refreshSkew:  defaultTokenRefreshSkew,
+		now:          time.Now,
+		sleep:        sleepContext,
+		observer:     nopObserver{},
+		apiInfoCache: &apiInfoCache{},
 	}
 
 	for _, o := range opts {
 		o(client)
 	}
+	err = client.applyOptions()
+	if err != nil {
+		return nil, err
+	}
 
-	err := client.Auth()
+	if client.lazyAuth || client.token != "" {
+		return client, nil
+	}
+
+	err = client.Auth()
 	if err != nil {
 		return nil, err
 	}
@@ -80,129 +238,555 @@ func NewClient(url, username, password string, opts ...ClientOption) (*Client, e
 	return client, nil
 }
 
+// applyOptions applies the settings of the options once all of them have been evaluated
+// and returns the first error of an option
+func (c *Client) applyOptions() error {
+	if c.optionErr != nil {
+		return c.optionErr
+	}
+	c.configureTransport()
+
+	if l, ok := c.logger.(*defaultLogger); ok && l.debug != c.debug {
+		// the default logger may be shared with the original of a clone
+		c.logger = &defaultLogger{debug: c.debug}
+	}
+
+	if c.credentials == nil {
+		p := newPasswordCredentialProvider(c.tokenURL(), c.username, c.password, c.scope, c.client)
+		p.skew = c.refreshSkew
+		p.now = c.now
+		c.credentials = p
+		c.ownCredentials = true
+	}
+
+	if c.token != "" {
+		if s, ok := c.credentials.(*sharedCredentials); ok {
+			s.setToken(c.token)
+		}
+	}
+
+	return nil
+}
+
 // Auth establishes a SSO session with oVirt API
 func (c *Client) Auth() error {
+	return c.auth(context.Background())
+}
+
+func (c *Client) auth(ctx context.Context) error {
+	if c.authenticator != nil {
+		return c.authenticator.Refresh()
+	}
+
+	_, err := c.credentials.Refresh(ctx, "")
+	return err
+}
+
+// requestToken requests a SSO token using the password grant and returns it with its lifetime
+// (0 if the SSO server does not report it)
+func requestToken(ctx context.Context, client *http.Client, tokenURL, username, password, scope string) (string, time.Duration, error) {
 	payload := url.Values{}
 
 	payload.Set("grant_type", "password")
-	payload.Set("scope", "ovirt-app-api")
-	payload.Set("username", c.username)
-	payload.Set("password", c.password)
+	payload.Set("scope", scope)
+	payload.Set("username", username)
+	payload.Set("password", password)
 
 	params := strings.NewReader(payload.Encode())
-	authURL := strings.TrimRight(c.url, "/api/") + "/sso/oauth/token"
 
-	req, err := http.NewRequest("POST", authURL, params)
+	req, err := http.NewRequestWithContext(ctx, "POST", tokenURL, params)
 	if err != nil {
-		return err
+		return "", 0, err
 	}
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 	req.Header.Set("Accept", "application/json")
 
-	resp, err := c.client.Do(req)
+	resp, err := client.Do(req)
 	if err != nil {
-		return err
+		return "", 0, err
 	}
+	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return err
+		return "", 0, err
 	}
 
 	var ssoResp ssoResponseJSON
 	err = json.Unmarshal(body, &ssoResp)
 	if err != nil {
-		return err
+		return "", 0, err
 	}
 
 	if ssoResp.SsoError != "" {
-		return errors.New(ssoResp.SsoError)
+		return "", 0, errors.New(ssoResp.SsoError)
 	}
 
 	if resp.StatusCode != 200 {
-		return errors.New(resp.Status)
+		return "", 0, errors.New(resp.Status)
 	}
 
-	c.accessToken = ssoResp.AccessToken
-	return nil
+	return ssoResp.AccessToken, time.Duration(ssoResp.ExpiresIn) * time.Second, nil
+}
+
+// ssoTokenURL derives the SSO token endpoint from the API URL
+func ssoTokenURL(apiURL string) string {
+	return engineBaseURL(apiURL) + "/sso/oauth/token"
+}
+
+// tokenURL returns the SSO token endpoint set with WithSSOURL or derived from the API URL
+func (c *Client) tokenURL() string {
+	if c.ssoURL != "" {
+		return c.ssoURL
+	}
+
+	return ssoTokenURL(c.url)
+}
+
+// revokeURL returns the SSO token revocation endpoint next to the token endpoint
+func (c *Client) revokeURL() string {
+	if c.ssoURL != "" {
+		return strings.TrimSuffix(strings.TrimRight(c.ssoURL, "/"), "/token") + "/revoke"
+	}
+
+	return engineBaseURL(c.url) + "/sso/oauth/revoke"
+}
+
+// engineBaseURL returns the base URL of the engine (the API URL without the /api suffix)
+func engineBaseURL(apiURL string) string {
+	return strings.TrimSuffix(strings.TrimRight(apiURL, "/"), "/api")
+}
+
+// Connect authenticates against the API unless a session was already established
+func (c *Client) Connect() error {
+	return c.connect(context.Background())
+}
+
+// connect makes sure there is a token. Requests are authorized individually if an Authenticator is used.
+func (c *Client) connect(ctx context.Context) error {
+	if c.authenticator != nil {
+		return nil
+	}
+
+	_, err := c.currentToken(ctx)
+	return err
+}
+
+// Token returns the current SSO token, e.g. to cache it and pass it to WithToken later.
+// It authenticates if there is no token yet.
+func (c *Client) Token() (string, error) {
+	return c.currentToken(context.Background())
+}
+
+// currentToken returns the token to use for the next request and authenticates if there is none
+func (c *Client) currentToken(ctx context.Context) (string, error) {
+	return c.credentials.Token(ctx)
+}
+
+// reauth replaces the rejected token. Concurrent requests rejected with the same token
+// share a single reauthentication.
+func (c *Client) reauth(ctx context.Context, rejected string) error {
+	if c.authenticator != nil {
+		return c.authenticator.Refresh()
+	}
+
+	_, err := c.credentials.Refresh(ctx, rejected)
+	return err
 }
 
 // GetAndParse retrieves XML data from the API and unmarshals it
-func (c *Client) GetAndParse(path string, v interface{}) error {
-	return c.SendAndParse(path, "GET", v, nil)
+func (c *Client) GetAndParse(path string, v interface{}, opts ...RequestOption) error {
+	return c.GetAndParseContext(context.Background(), path, v, opts...)
+}
+
+// GetAndParseContext retrieves XML data from the API and unmarshals it
+func (c *Client) GetAndParseContext(ctx context.Context, path string, v interface{}, opts ...RequestOption) error {
+	return c.SendAndParseContext(ctx, path, "GET", v, nil, opts...)
 }
 
 // Get retrieves XML data from the API and returns it
-func (c *Client) Get(path string) ([]byte, error) {
-	return c.SendRequest(path, "GET", nil)
+func (c *Client) Get(path string, opts ...RequestOption) ([]byte, error) {
+	return c.GetContext(context.Background(), path, opts...)
+}
+
+// GetContext retrieves XML data from the API and returns it
+func (c *Client) GetContext(ctx context.Context, path string, opts ...RequestOption) ([]byte, error) {
+	return c.SendRequestContext(ctx, path, "GET", nil, opts...)
+}
+
+// Post sends XML data to the API (e.g. to create an entity) and returns the response
+func (c *Client) Post(path string, body io.Reader, opts ...RequestOption) ([]byte, error) {
+	return c.SendRequest(path, "POST", body, opts...)
+}
+
+// PostAndParse sends XML data to the API and unmarshals the response
+func (c *Client) PostAndParse(path string, res interface{}, body io.Reader, opts ...RequestOption) error {
+	return c.SendAndParse(path, "POST", res, body, opts...)
+}
+
+// Put sends XML data to the API (e.g. to update an entity) and returns the response
+func (c *Client) Put(path string, body io.Reader, opts ...RequestOption) ([]byte, error) {
+	return c.SendRequest(path, "PUT", body, opts...)
+}
+
+// PutAndParse sends XML data to the API and unmarshals the response
+func (c *Client) PutAndParse(path string, res interface{}, body io.Reader, opts ...RequestOption) error {
+	return c.SendAndParse(path, "PUT", res, body, opts...)
+}
+
+// Delete removes an entity and returns the response
+func (c *Client) Delete(path string, opts ...RequestOption) ([]byte, error) {
+	return c.SendRequest(path, "DELETE", nil, opts...)
+}
+
+// Close terminates the SSO session by revoking the current token and closes idle connections.
+// Nothing is revoked if the client has not authenticated yet, uses an Authenticator or a credential
+// provider passed with WithCredentialProvider (which owns its tokens). Clients sharing the token
+// lose the session as well. The connections of a transport shared with a client passed with
+// WithHTTPClient or with the original of a clone are left open. Close can be called multiple times.
+func (c *Client) Close() error {
+	if !c.sharedTransport {
+		defer c.client.CloseIdleConnections()
+	}
+
+	if c.authenticator != nil {
+		return nil
+	}
+
+	token := c.cachedToken()
+	if token == "" {
+		return nil
+	}
+
+	err := revokeToken(context.Background(), c.client, c.revokeURL(), token)
+	if err != nil {
+		return err
+	}
+
+	if s, ok := c.credentials.(*sharedCredentials); ok {
+		s.clearToken(token)
+	}
+
+	return nil
 }
 
-// Close terminates the SSO session with the API
-func (c *Client) Close() {
-	req, err := http.NewRequest("HEAD", c.url, nil)
+// cachedToken returns the current token of the built-in credential provider without authenticating
+func (c *Client) cachedToken() string {
+	s, ok := c.credentials.(*sharedCredentials)
+	if !ok || !c.ownCredentials {
+		return ""
+	}
+
+	return s.cachedToken()
+}
+
+// revokeToken invalidates the SSO token on the SSO server
+func revokeToken(ctx context.Context, client *http.Client, revokeURL, token string) error {
+	payload := url.Values{}
+	payload.Set("token", token)
+
+	req, err := http.NewRequestWithContext(ctx, "POST", revokeURL, strings.NewReader(payload.Encode()))
 	if err != nil {
-		return
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
 	}
+	defer resp.Body.Close()
 
-	req.SetBasicAuth(c.username, c.password)
-	c.client.Do(req)
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	var ssoResp ssoResponseJSON
+	if json.Unmarshal(body, &ssoResp) == nil && ssoResp.SsoError != "" {
+		return fmt.Errorf("could not revoke token: %s", ssoResp.SsoError)
+	}
+
+	if resp.StatusCode != 200 {
+		return fmt.Errorf("could not revoke token: %s", resp.Status)
+	}
+
+	return nil
 }
 
 // SendAndParse sends a request to the API and unmarshalls the response
-func (c *Client) SendAndParse(path, method string, res interface{}, body io.Reader) error {
-	b, err := c.SendRequest(path, method, body)
+func (c *Client) SendAndParse(path, method string, res interface{}, body io.Reader, opts ...RequestOption) error {
+	return c.SendAndParseContext(context.Background(), path, method, res, body, opts...)
+}
+
+// SendAndParseContext sends a request to the API and unmarshalls the response.
+// JSON responses (see JSON) are decoded with encoding/json, all others as XML.
+// res is left unchanged if it is nil or the response has no body (e.g. 204 No Content).
+func (c *Client) SendAndParseContext(ctx context.Context, path, method string, res interface{}, body io.Reader, opts ...RequestOption) error {
+	resp, err := c.SendRawContext(ctx, path, method, body, opts...)
 	if err != nil {
 		return err
 	}
 
-	err = xml.Unmarshal(b, res)
-	return err
+	return decodeResponse(resp, res)
 }
 
-// SendRequest sends a request to the API
-func (c *Client) SendRequest(path, method string, body io.Reader) ([]byte, error) {
-	return c.sendRequest(path, method, body, true)
+// decodeResponse unmarshals the body of resp into res unless there is nothing to decode
+func decodeResponse(resp *Response, res interface{}) error {
+	if res == nil || resp.StatusCode == http.StatusNoContent || len(bytes.TrimSpace(resp.Body)) == 0 {
+		return nil
+	}
+
+	if isJSON(resp.Header) {
+		return json.Unmarshal(resp.Body, res)
+	}
+
+	return xml.Unmarshal(resp.Body, res)
 }
 
-func (c *Client) sendRequest(path, method string, body io.Reader, reauth bool) ([]byte, error) {
-	uri := strings.Trim(c.url, "/") + "/" + strings.Trim(path, "/")
-	c.logger.Debugf("%s", method, uri)
+// SendObject marshals obj as XML request body (nil sends no body) and unmarshals the response into res (if not nil)
+func (c *Client) SendObject(path, method string, obj, res interface{}, opts ...RequestOption) error {
+	return c.SendObjectContext(context.Background(), path, method, obj, res, opts...)
+}
 
-	req, err := http.NewRequest(method, uri, body)
+// SendObjectContext marshals obj as XML request body (nil sends no body) and unmarshals the response into res (if not nil)
+func (c *Client) SendObjectContext(ctx context.Context, path, method string, obj, res interface{}, opts ...RequestOption) error {
+	resp, err := c.send(ctx, path, method, obj, opts)
+	if err != nil {
+		return err
+	}
+
+	return decodeResponse(resp, res)
+}
+
+// send marshals obj (if not nil) as request body
+func (c *Client) send(ctx context.Context, path, method string, obj interface{}, opts []RequestOption) (*Response, error) {
+	var payload []byte
+	if obj != nil {
+		b, err := xml.Marshal(obj)
+		if err != nil {
+			return nil, fmt.Errorf("could not marshal request body: %w", err)
+		}
+
+		if len(b) > 0 {
+			payload = b
+		}
+	}
+
+	return c.sendRequest(ctx, path, method, payload, true, opts)
+}
+
+// SendRequest sends a request to the API
+func (c *Client) SendRequest(path, method string, body io.Reader, opts ...RequestOption) ([]byte, error) {
+	return c.SendRequestContext(context.Background(), path, method, body, opts...)
+}
+
+// SendRequestContext sends a request to the API. The request (including a reauthentication
+// triggered by it) is aborted when ctx is done.
+func (c *Client) SendRequestContext(ctx context.Context, path, method string, body io.Reader, opts ...RequestOption) ([]byte, error) {
+	resp, err := c.SendRawContext(ctx, path, method, body, opts...)
 	if err != nil {
 		return nil, err
 	}
 
-	req.Header.Add("Content-Type", "application/xml")
-	req.Header.Set("Accept", "application/xml")
-	req.Header.Set("Authorization", "Bearer "+c.accessToken)
+	return resp.Body, nil
+}
 
-	resp, err := c.client.Do(req)
+// SendRaw sends a request to the API and returns the response including status code and headers
+// (e.g. to detect 202 Accepted or to read the Location header of a created entity)
+func (c *Client) SendRaw(path, method string, body io.Reader, opts ...RequestOption) (*Response, error) {
+	return c.SendRawContext(context.Background(), path, method, body, opts...)
+}
+
+// SendRawContext sends a request to the API and returns the response including status code and headers
+func (c *Client) SendRawContext(ctx context.Context, path, method string, body io.Reader, opts ...RequestOption) (*Response, error) {
+	payload, err := readPayload(body)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode == 401 && reauth {
-		err := c.Auth()
-		if err == nil {
-			return c.sendRequest(path, method, body, false)
-		}
+	return c.sendRequest(ctx, path, method, payload, true, opts)
+}
+
+func readPayload(body io.Reader) ([]byte, error) {
+	if body == nil {
+		return nil, nil
 	}
 
-	if resp.StatusCode >= 300 {
-		return nil, fmt.Errorf(resp.Status)
+	return io.ReadAll(body)
+}
+
+// sendRequestOnce sends payload (which is kept in memory so it can be sent again after reauth
+// or a retry), reauthenticating once if the token is rejected
+func (c *Client) sendRequestOnce(ctx context.Context, path, method string, payload []byte, kind RequestKind, reauth bool, opts []RequestOption) (*Response, error) {
+	ex, err := c.doRequest(ctx, path, method, payload, kind, opts)
+	if err != nil {
+		return nil, err
 	}
+	resp := ex.resp
 
 	b, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	c.requestDone(ex.req, kind, resp, len(payload), len(b), ex.start, err)
 	if err != nil {
 		return nil, err
 	}
 
+	if resp.StatusCode == 401 && reauth {
+		retry, err := c.handleRejected(ctx, ex)
+		if err != nil {
+			return nil, err
+		}
+
+		if retry {
+			// reauth is false for the repeated request, so it is sent at most once
+			res, err := c.sendRequestOnce(ctx, path, method, payload, ReauthAttempt, false, opts)
+			if isStatus(err, http.StatusUnauthorized) {
+				return nil, fmt.Errorf("%w: %w", ErrReauthFailed, err)
+			}
+
+			return res, err
+		}
+	}
+
+	if resp.StatusCode >= 300 {
+		return nil, ex.apiError(b)
+	}
+
 	c.logger.Debugf("Status Code: %s", resp.Status)
 	if c.debug {
 		c.logger.Debugf("Response: %s", string(b))
 	}
 
-	return b, err
+	return &Response{
+		StatusCode:    resp.StatusCode,
+		Header:        resp.Header,
+		Body:          b,
+		CorrelationID: ex.correlationID,
+		ETag:          resp.Header.Get("ETag"),
+	}, nil
+}
+
+// exchange is a request sent to the API and the response with its body not read yet
+type exchange struct {
+	req           *http.Request
+	resp          *http.Response
+	token         string
+	correlationID string
+	start         time.Time
+}
+
+// doRequest builds the request with the headers of the client and sends it.
+// The caller has to close the body of the response.
+func (c *Client) doRequest(ctx context.Context, path, method string, payload []byte, kind RequestKind, opts []RequestOption) (*exchange, error) {
+	token := ""
+	if c.authenticator == nil {
+		t, err := c.currentToken(ctx)
+		if err != nil {
+			return nil, err
+		}
+		token = t
+	}
+
+	body, encoding, err := c.encodeBody(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	uri, err := c.requestURL(path)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, uri, body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("Content-Type", "application/xml")
+	if encoding != "" {
+		req.Header.Set("Content-Encoding", encoding)
+	}
+	if c.expectContinue > 0 && len(payload) > 0 {
+		req.Header.Set("Expect", "100-continue")
+	}
+	req.Header.Set("Accept", "application/xml")
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+	if c.authenticator != nil {
+		err = c.authenticator.Authorize(req)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	if c.filter {
+		req.Header.Set("Filter", "true")
+	}
+	for k, v := range c.headers {
+		req.Header[k] = v
+	}
+	if c.correlationID != nil {
+		req.Header.Set(correlationIDHeader, c.correlationID())
+	}
+	for _, o := range opts {
+		o(req)
+	}
+
+	correlationID := req.Header.Get(correlationIDHeader)
+	if correlationID != "" {
+		c.logger.Debugf("%s %s (correlation id: %s)", method, uri, correlationID)
+	} else {
+		c.logger.Debugf("%s %s", method, uri)
+	}
+
+	err = c.rateLimiter.wait(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	resp, err := c.client.Do(req)
+	if err != nil {
+		c.requestDone(req, kind, nil, len(payload), 0, start, err)
+		return nil, err
+	}
+
+	return &exchange{
+		req:           req,
+		resp:          resp,
+		token:         token,
+		correlationID: correlationID,
+		start:         start,
+	}, nil
+}
+
+// handleRejected reauthenticates after the token of ex was rejected and reports if the
+// request should be sent again
+func (c *Client) handleRejected(ctx context.Context, ex *exchange) (bool, error) {
+	err := c.reauth(ctx, ex.token)
+	if ctx.Err() != nil {
+		return false, ctx.Err()
+	}
+
+	if errors.Is(err, ErrNoCredentials) {
+		return false, fmt.Errorf("token was rejected (%s): %w", ex.resp.Status, err)
+	}
+
+	return err == nil, nil
+}
+
+// apiError returns the error for the error status of the response with body b
+func (ex *exchange) apiError(b []byte) *APIError {
+	return &APIError{
+		StatusCode:            ex.resp.StatusCode,
+		Status:                ex.resp.Status,
+		Header:                ex.resp.Header,
+		CorrelationID:         ex.correlationID,
+		ResponseCorrelationID: ex.resp.Header.Get(correlationIDHeader),
+		Fault:                 parseFault(b),
+	}
 }
@@ -0,0 +1,819 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWithHTTPClient(t *testing.T) {
+	e := newTestEngine(t)
+	e.handle("/vms", func(w http.ResponseWriter, r *http.Request) {
+		writeXML(w, http.StatusOK, "<vms/>")
+	})
+
+	orders := map[string]func(hc *http.Client) []ClientOption{
+		"client first": func(hc *http.Client) []ClientOption {
+			return []ClientOption{WithHTTPClient(hc), WithLogger(&testLogger{}), WithDebug()}
+		},
+		"client last": func(hc *http.Client) []ClientOption {
+			return []ClientOption{WithLogger(&testLogger{}), WithDebug(), WithHTTPClient(hc)}
+		},
+	}
+
+	for name, opts := range orders {
+		t.Run(name, func(t *testing.T) {
+			rt := &recordingTransport{}
+			c := e.client(t, opts(&http.Client{Transport: rt})...)
+
+			_, err := c.Get("/vms")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			want := []string{"/ovirt-engine/sso/oauth/token", testAPIPath + "/vms"}
+			if got := rt.requests(); !reflect.DeepEqual(got, want) {
+				t.Fatalf("expected requests %v through the http client, got %v", want, got)
+			}
+		})
+	}
+}
+
+func TestWithHTTPClientNotModified(t *testing.T) {
+	tr := http.DefaultTransport.(*http.Transport).Clone()
+	hc := &http.Client{Transport: tr}
+
+	c, err := NewClient("https://engine/ovirt-engine/api", "", "", WithToken("token"), WithHTTPClient(hc),
+		WithInsecure(), WithTimeout(time.Second), WithTransportConfig(10, 5, 5, time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if hc.Timeout != 0 || hc.Transport != tr {
+		t.Fatal("the http client passed with WithHTTPClient was modified")
+	}
+
+	if (tr.TLSClientConfig != nil && tr.TLSClientConfig.InsecureSkipVerify) || tr.MaxIdleConnsPerHost != 0 {
+		t.Fatal("the transport of the http client passed with WithHTTPClient was modified")
+	}
+
+	ctr := c.client.Transport.(*http.Transport)
+	if c.client.Timeout != time.Second || !ctr.TLSClientConfig.InsecureSkipVerify || ctr.MaxIdleConnsPerHost != 5 {
+		t.Fatal("options were not applied to the copy of the http client")
+	}
+}
+
+func TestWithHTTPClientDefaultClient(t *testing.T) {
+	_, err := NewClient("https://engine/ovirt-engine/api", "", "", WithToken("token"), WithHTTPClient(http.DefaultClient), WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if http.DefaultClient.Transport != nil {
+		t.Fatal("WithInsecure modified http.DefaultClient")
+	}
+
+	if cfg := http.DefaultTransport.(*http.Transport).TLSClientConfig; cfg != nil && cfg.InsecureSkipVerify {
+		t.Fatal("WithInsecure modified http.DefaultTransport")
+	}
+}
+
+func TestWithHTTPClientSharesTransport(t *testing.T) {
+	tr := &recordingTransport{}
+
+	c, err := NewClient("https://engine/ovirt-engine/api", "", "", WithToken("token"), WithHTTPClient(&http.Client{Transport: tr}), WithTimeout(time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if c.client.Transport != tr {
+		t.Fatal("transport was replaced although no option modifies it")
+	}
+}
+
+func TestReauthCancelled(t *testing.T) {
+	e := newTestEngine(t)
+	e.mux.HandleFunc("/slow/sso/oauth/token", func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(500 * time.Millisecond)
+		w.Write([]byte(`{"access_token":"new"}`))
+	})
+	e.handle("/vms", func(w http.ResponseWriter, r *http.Request) {
+		writeXML(w, http.StatusUnauthorized, "")
+	})
+	c := e.client(t, WithToken("expired"), WithSSOURL(e.URL+"/slow/sso/oauth/token"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := c.GetContext(ctx, "/vms")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+
+	if d := time.Since(start); d >= 500*time.Millisecond {
+		t.Fatalf("request returned after %s instead of when the context was done", d)
+	}
+}
+
+func TestConcurrentReauth(t *testing.T) {
+	e := newTestEngine(t)
+	var requests int32
+	e.handle("/vms", func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) == 1 {
+			writeXML(w, http.StatusUnauthorized, "")
+			return
+		}
+
+		writeXML(w, http.StatusOK, "<vms/>")
+	})
+	c := e.client(t)
+
+	const n = 50
+	errs := make(chan error, n)
+	wg := sync.WaitGroup{}
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			_, err := c.SendRequest("/vms", "GET", nil)
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if got := atomic.LoadInt32(&requests); got != n+1 {
+		t.Fatalf("expected %d requests (including the repeated one), got %d", n+1, got)
+	}
+
+	// the initial authentication and the reauthentication after the 401
+	if got := e.tokens(); got != 2 {
+		t.Fatalf("expected 2 token requests, got %d", got)
+	}
+}
+
+func TestConcurrentReauthSingleAuth(t *testing.T) {
+	e := newTestEngine(t)
+
+	// all requests are held until every one of them arrived with the first token, so they are
+	// rejected at the same time
+	const n = 20
+	var stale int32
+	barrier := make(chan struct{})
+	e.handle("/vms", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "Bearer token-1" {
+			if atomic.AddInt32(&stale, 1) == n {
+				close(barrier)
+			}
+			select {
+			case <-barrier:
+			case <-time.After(5 * time.Second):
+				t.Error("not all requests were sent with the first token")
+			}
+
+			writeXML(w, http.StatusUnauthorized, "")
+			return
+		}
+
+		writeXML(w, http.StatusOK, "<vms/>")
+	})
+	c := e.client(t)
+
+	errs := make(chan error, n)
+	for i := 0; i < n; i++ {
+		go func() {
+			_, err := c.Get("/vms")
+			errs <- err
+		}()
+	}
+	for i := 0; i < n; i++ {
+		err := <-errs
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if got := atomic.LoadInt32(&stale); got != n {
+		t.Fatalf("expected %d requests with the first token, got %d", n, got)
+	}
+	// the initial authentication and a single reauthentication shared by all rejected requests
+	if got := e.tokens(); got != 2 {
+		t.Fatalf("expected 2 token requests, got %d", got)
+	}
+}
+
+func TestSSOTokenURL(t *testing.T) {
+	tests := map[string]string{
+		"https://a.pi/api":                  "https://a.pi/sso/oauth/token",
+		"https://x/api/":                    "https://x/sso/oauth/token",
+		"https://x/ovirt-engine":            "https://x/ovirt-engine/sso/oauth/token",
+		"https://ovirtapi/ovirt-engine/api": "https://ovirtapi/ovirt-engine/sso/oauth/token",
+	}
+
+	for apiURL, want := range tests {
+		if got := ssoTokenURL(apiURL); got != want {
+			t.Errorf("%s: expected %s, got %s", apiURL, want, got)
+		}
+	}
+}
+
+func TestWithSSOURL(t *testing.T) {
+	e := newTestEngine(t)
+	rt := &recordingTransport{}
+	issued := e.handleExpiringTokens("/prefix/ovirt-engine/sso/oauth/token", time.Hour)
+	e.mux.HandleFunc("/prefix/ovirt-engine/sso/oauth/revoke", func(w http.ResponseWriter, r *http.Request) {})
+	e.handle("/vms", func(w http.ResponseWriter, r *http.Request) {
+		writeXML(w, http.StatusOK, "<vms/>")
+	})
+
+	c := e.client(t, WithHTTPClient(&http.Client{Transport: rt}), WithSSOURL(e.URL+"/prefix/ovirt-engine/sso/oauth/token"))
+	_, err := c.Get("/vms")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	err = c.Close()
+	if err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	want := "/prefix/ovirt-engine/sso/oauth/token,/ovirt-engine/api/vms,/prefix/ovirt-engine/sso/oauth/revoke"
+	if got := strings.Join(rt.requests(), ","); got != want {
+		t.Fatalf("expected the requests %s, got %s", want, got)
+	}
+	if atomic.LoadInt32(issued) != 1 || e.tokens() != 0 {
+		t.Fatalf("expected the token from the configured endpoint only, got %d and %d", atomic.LoadInt32(issued), e.tokens())
+	}
+
+	// without the option the endpoint is derived from the API URL
+	rt = &recordingTransport{}
+	_, err = e.client(t, WithHTTPClient(&http.Client{Transport: rt})).Get("/vms")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if got := rt.requests()[0]; got != "/ovirt-engine/sso/oauth/token" {
+		t.Fatalf("expected the derived token endpoint, got %s", got)
+	}
+}
+
+func TestConvenienceMethods(t *testing.T) {
+	e := newTestEngine(t)
+	e.handle("/vms", func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		w.Header().Set("X-Method", r.Method)
+		w.Header().Set("X-Content-Type", r.Header.Get("Content-Type"))
+		writeXML(w, http.StatusOK, string(b))
+	})
+	c := e.client(t)
+
+	body := `<vm><name>test</name></vm>`
+	b, err := c.Post("/vms", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(b) != body {
+		t.Fatalf("expected the posted body to be echoed, got %q", b)
+	}
+
+	methods := map[string]func() (*Response, error){
+		"POST":   func() (*Response, error) { return c.SendRaw("/vms", "POST", strings.NewReader(body)) },
+		"PUT":    func() (*Response, error) { return c.SendRaw("/vms", "PUT", strings.NewReader(body)) },
+		"DELETE": func() (*Response, error) { return c.SendRaw("/vms", "DELETE", nil) },
+	}
+	for method, send := range methods {
+		resp, err := send()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := resp.Header.Get("X-Method"); got != method {
+			t.Errorf("expected %s, got %s", method, got)
+		}
+
+		if got := resp.Header.Get("X-Content-Type"); got != "application/xml" {
+			t.Errorf("%s: expected Content-Type application/xml, got %q", method, got)
+		}
+	}
+
+	vm := &VM{}
+	err = c.PutAndParse("/vms", vm, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if vm.Name != "test" {
+		t.Fatalf("unexpected vm %+v", vm)
+	}
+
+	_, err = c.Delete("/vms")
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSendObject(t *testing.T) {
+	e := newTestEngine(t)
+	e.handle("/vms", func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		if len(b) == 0 {
+			b = []byte(`<vm><name>empty</name></vm>`)
+		}
+		writeXML(w, http.StatusOK, string(b))
+	})
+	c := e.client(t)
+
+	res := &VM{}
+	err := c.SendObject("/vms", "POST", &VM{Name: "test", Memory: 1 << 30}, res)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res.Name != "test" || res.Memory != 1<<30 {
+		t.Fatalf("unexpected vm %+v", res)
+	}
+
+	res = &VM{}
+	err = c.SendObject("/vms", "POST", nil, res)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res.Name != "empty" {
+		t.Fatalf("expected a request without body, got %+v", res)
+	}
+
+	err = c.SendObject("/vms", "POST", make(chan int), nil)
+	if err == nil || !strings.Contains(err.Error(), "could not marshal request body") {
+		t.Fatalf("expected a marshal error, got %v", err)
+	}
+}
+
+func TestWithToken(t *testing.T) {
+	e := newTestEngine(t)
+	e.handle("/vms", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "Bearer cached" {
+			writeXML(w, http.StatusUnauthorized, "")
+			return
+		}
+
+		w.Header().Set("X-Authorization", r.Header.Get("Authorization"))
+		writeXML(w, http.StatusOK, "<vms/>")
+	})
+
+	c := e.client(t, WithToken("cached"))
+	if n := e.tokens(); n != 0 {
+		t.Fatalf("NewClient authenticated although a token was passed (%d token requests)", n)
+	}
+
+	resp, err := c.SendRaw("/vms", "GET", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := resp.Header.Get("X-Authorization"); got != "Bearer token-1" {
+		t.Fatalf("expected the request to be repeated with a new token, got %q", got)
+	}
+
+	c, err = NewClient(e.apiURL(), "", "", WithToken("cached"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = c.Get("/vms")
+	if !errors.Is(err, ErrNoCredentials) {
+		t.Fatalf("expected ErrNoCredentials, got %v", err)
+	}
+}
+
+func TestWithFilter(t *testing.T) {
+	e := newTestEngine(t)
+	hr := &headerRecorder{body: "<vms/>"}
+	e.handle("/vms", hr.ServeHTTP)
+
+	for _, filter := range []bool{false, true} {
+		opts := []ClientOption{}
+		if filter {
+			opts = append(opts, WithFilter())
+		}
+
+		_, err := e.client(t, opts...).Get("/vms")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got := hr.last().Get("Filter")
+		if filter && got != "true" {
+			t.Errorf("expected Filter: true with WithFilter, got %q", got)
+		}
+
+		if !filter && got != "" {
+			t.Errorf("unexpected Filter header %q without WithFilter", got)
+		}
+	}
+}
+
+func TestSendRaw(t *testing.T) {
+	e := newTestEngine(t)
+	e.handle("/vms", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Location", e.apiURL()+"/vms/123")
+		w.Header().Set("X-Custom", "value")
+		writeXML(w, http.StatusAccepted, `<vm id="123"/>`)
+	})
+	c := e.client(t)
+
+	resp, err := c.SendRaw("/vms", "POST", strings.NewReader("<vm/>"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("expected status 202, got %d", resp.StatusCode)
+	}
+
+	if resp.Header.Get("X-Custom") != "value" || resp.Header.Get("Location") != e.apiURL()+"/vms/123" {
+		t.Fatalf("headers were not propagated: %v", resp.Header)
+	}
+
+	if string(resp.Body) != `<vm id="123"/>` {
+		t.Fatalf("unexpected body %q", resp.Body)
+	}
+}
+
+func TestCloseRevokesToken(t *testing.T) {
+	e := newTestEngine(t)
+	revoked := []string{}
+	e.mux.HandleFunc("/ovirt-engine/sso/oauth/revoke", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" {
+			t.Errorf("expected revoke with POST, got %s", r.Method)
+		}
+		revoked = append(revoked, r.FormValue("token"))
+		w.Write([]byte("{}"))
+	})
+	c := e.client(t)
+
+	err := c.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(revoked) != 1 || revoked[0] != "token-1" {
+		t.Fatalf("expected token-1 to be revoked, got %v", revoked)
+	}
+
+	if token := c.cachedToken(); token != "" {
+		t.Fatalf("token %s was not cleared", token)
+	}
+}
+
+func TestCloseRevokeFailed(t *testing.T) {
+	e := newTestEngine(t)
+	e.mux.HandleFunc("/ovirt-engine/sso/oauth/revoke", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error":"invalid_token"}`))
+	})
+	c := e.client(t)
+
+	err := c.Close()
+	if err == nil || !strings.Contains(err.Error(), "invalid_token") {
+		t.Fatalf("expected the revoke error, got %v", err)
+	}
+}
+
+func TestWithScope(t *testing.T) {
+	e := newTestEngine(t)
+
+	e.client(t)
+	form := e.lastTokenForm()
+	if got := form.Get("scope"); got != "ovirt-app-api" {
+		t.Fatalf("expected the default scope ovirt-app-api, got %q", got)
+	}
+
+	if form.Get("grant_type") != "password" || form.Get("username") != "user" || form.Get("password") != "secret" {
+		t.Fatalf("unexpected token request %v", form)
+	}
+
+	e.client(t, WithScope("ovirt-app-admin ovirt-app-portal"))
+	if got := e.lastTokenForm().Get("scope"); got != "ovirt-app-admin ovirt-app-portal" {
+		t.Fatalf("expected the scope set with WithScope, got %q", got)
+	}
+}
+
+func TestWithUserAgent(t *testing.T) {
+	e := newTestEngine(t)
+	hr := &headerRecorder{body: "<vms/>"}
+	e.handle("/vms", hr.ServeHTTP)
+
+	_, err := e.client(t).Get("/vms")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if got := hr.last().Get("User-Agent"); got != defaultUserAgent || !strings.HasPrefix(got, "ovirt_api-go/") {
+		t.Fatalf("expected the default user agent, got %q", got)
+	}
+
+	_, err = e.client(t, WithUserAgent("backup-tool/2.1")).Get("/vms")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if got := hr.last().Get("User-Agent"); got != "backup-tool/2.1" {
+		t.Fatalf("expected the user agent set with WithUserAgent, got %q", got)
+	}
+}
+
+func TestEmptyResponse(t *testing.T) {
+	e := newTestEngine(t)
+	e.handle("/vms/123", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "DELETE" {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.Header().Set("Content-Type", "application/xml")
+	})
+	c := e.client(t)
+
+	b, err := c.Delete("/vms/123")
+	if err != nil || len(b) != 0 {
+		t.Fatalf("Delete: %q, %v", b, err)
+	}
+
+	vm := &VM{Name: "unchanged"}
+	err = c.SendAndParse("/vms/123", "DELETE", vm, nil)
+	if err != nil {
+		t.Fatalf("expected no error for 204, got %v", err)
+	}
+
+	err = c.GetAndParse("/vms/123", vm)
+	if err != nil {
+		t.Fatalf("expected no error for an empty body, got %v", err)
+	}
+	if vm.Name != "unchanged" {
+		t.Fatalf("expected the result to be left untouched, got %+v", vm)
+	}
+
+	err = c.SendAndParse("/vms/123", "DELETE", nil, nil)
+	if err != nil {
+		t.Fatalf("expected no error without result, got %v", err)
+	}
+}
+
+func TestWithLazyAuth(t *testing.T) {
+	// nothing listens on the URL, so creating the client must not send any request
+	_, err := NewClient(closedURL(t)+testAPIPath, "user", "secret", WithLazyAuth())
+	if err != nil {
+		t.Fatalf("NewClient with an unreachable engine: %v", err)
+	}
+
+	e := newTestEngine(t)
+	rt := &recordingTransport{}
+	e.handle("/vms", func(w http.ResponseWriter, r *http.Request) {
+		writeXML(w, http.StatusOK, "<vms/>")
+	})
+	c := e.client(t, WithLazyAuth(), WithHTTPClient(&http.Client{Transport: rt}))
+	if n := len(rt.requests()); n != 0 {
+		t.Fatalf("expected no request when creating the client, got %v", rt.requests())
+	}
+
+	// concurrent first requests share the authentication
+	wg := sync.WaitGroup{}
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			_, err := c.Get("/vms")
+			if err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := e.tokens(); n != 1 {
+		t.Fatalf("expected a single authentication, got %d", n)
+	}
+	if got := rt.requests()[0]; got != "/ovirt-engine/sso/oauth/token" {
+		t.Fatalf("expected the first request to authenticate, got %s", got)
+	}
+}
+
+// trackingDialer records the connections of a transport
+type trackingDialer struct {
+	mu    sync.Mutex
+	conns []*trackedConn
+}
+
+type trackedConn struct {
+	net.Conn
+	closed int32
+}
+
+func (c *trackedConn) Close() error {
+	atomic.StoreInt32(&c.closed, 1)
+	return c.Conn.Close()
+}
+
+func (d *trackingDialer) dial(ctx context.Context, network, addr string) (net.Conn, error) {
+	conn, err := (&net.Dialer{}).DialContext(ctx, network, addr)
+	if err != nil {
+		return nil, err
+	}
+
+	tc := &trackedConn{Conn: conn}
+	d.mu.Lock()
+	d.conns = append(d.conns, tc)
+	d.mu.Unlock()
+
+	return tc, nil
+}
+
+// open returns the number of connections not closed yet
+func (d *trackingDialer) open() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	n := 0
+	for _, c := range d.conns {
+		if atomic.LoadInt32(&c.closed) == 0 {
+			n++
+		}
+	}
+
+	return n
+}
+
+func TestCloseTwice(t *testing.T) {
+	e := newTestEngine(t)
+	var revoked int32
+	e.mux.HandleFunc("/ovirt-engine/sso/oauth/revoke", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&revoked, 1)
+		w.Write([]byte("{}"))
+	})
+	e.handle("/vms", func(w http.ResponseWriter, r *http.Request) {
+		writeXML(w, http.StatusOK, "<vms/>")
+	})
+
+	d := &trackingDialer{}
+	c := e.client(t)
+	// track the connections from here on, dropping the one used to authenticate
+	c.transport().DialContext = d.dial
+	c.transport().CloseIdleConnections()
+	_, err := c.Get("/vms")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if d.open() == 0 {
+		t.Fatal("expected an idle connection before Close")
+	}
+
+	for i := 0; i < 2; i++ {
+		err = c.Close()
+		if err != nil {
+			t.Fatalf("Close %d: %v", i+1, err)
+		}
+
+		if n := d.open(); n != 0 {
+			t.Fatalf("expected the idle connections to be closed, %d still open", n)
+		}
+	}
+
+	if n := atomic.LoadInt32(&revoked); n != 1 {
+		t.Fatalf("expected the token to be revoked once, got %d revocations", n)
+	}
+}
+
+func TestCloseKeepsDefaultTransportConnections(t *testing.T) {
+	e := newTestEngine(t)
+	e.mux.HandleFunc("/ovirt-engine/sso/oauth/revoke", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("{}"))
+	})
+
+	var dialed int32
+	other := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	other.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt32(&dialed, 1)
+		}
+	}
+	other.Start()
+	defer other.Close()
+
+	get := func() {
+		resp, err := http.Get(other.URL)
+		if err != nil {
+			t.Fatalf("Get: %v", err)
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}
+
+	get()
+	c := e.client(t)
+	err := c.Close()
+	if err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	get()
+
+	if n := atomic.LoadInt32(&dialed); n != 1 {
+		t.Fatalf("expected the idle connection of http.DefaultTransport to be reused, got %d connections", n)
+	}
+}
+
+func TestCloseCloneKeepsConnections(t *testing.T) {
+	e := newTestEngine(t)
+	e.mux.HandleFunc("/ovirt-engine/sso/oauth/revoke", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("{}"))
+	})
+	e.handle("/vms", func(w http.ResponseWriter, r *http.Request) {
+		writeXML(w, http.StatusOK, "<vms/>")
+	})
+
+	d := &trackingDialer{}
+	c := e.client(t)
+	// track the connections from here on, dropping the one used to authenticate
+	c.transport().DialContext = d.dial
+	c.transport().CloseIdleConnections()
+	_, err := c.Get("/vms")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+
+	clone, err := c.Clone()
+	if err != nil {
+		t.Fatalf("Clone: %v", err)
+	}
+	err = clone.Close()
+	if err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	if d.open() == 0 {
+		t.Fatal("expected the connections of the original to be left open")
+	}
+}
+
+// staticProvider is a CredentialProvider returning a fixed token
+type staticProvider string
+
+func (p staticProvider) Token(ctx context.Context) (string, error) {
+	return string(p), nil
+}
+
+func (p staticProvider) Refresh(ctx context.Context, rejected string) (string, error) {
+	return string(p), nil
+}
+
+func TestCloseCloneWithCredentialProvider(t *testing.T) {
+	e := newTestEngine(t)
+	var revoked int32
+	e.mux.HandleFunc("/ovirt-engine/sso/oauth/revoke", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&revoked, 1)
+		w.Write([]byte("{}"))
+	})
+	e.handle("/vms", func(w http.ResponseWriter, r *http.Request) {
+		writeXML(w, http.StatusOK, "<vms/>")
+	})
+	c := e.client(t)
+
+	clone, err := c.Clone(WithCredentialProvider(staticProvider("provided")))
+	if err != nil {
+		t.Fatalf("Clone: %v", err)
+	}
+	_, err = clone.Get("/vms")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+
+	// the provider owns its token, so nothing is revoked
+	for i := 0; i < 2; i++ {
+		err = clone.Close()
+		if err != nil {
+			t.Fatalf("Close %d: %v", i+1, err)
+		}
+	}
+	if n := atomic.LoadInt32(&revoked); n != 0 {
+		t.Fatalf("expected no revocation for the clone, got %d", n)
+	}
+
+	err = c.Close()
+	if err != nil || atomic.LoadInt32(&revoked) != 1 {
+		t.Fatalf("expected the token of the original to be revoked, got %d revocations (%v)", atomic.LoadInt32(&revoked), err)
+	}
+}
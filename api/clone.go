@@ -0,0 +1,60 @@
+package api
+
+import "net/http"
+
+// WithHeader adds a header sent with every request of the client (e.g. Correlation-Id or Filter)
+func WithHeader(key, value string) ClientOption {
+	return func(c *Client) {
+		if c.headers == nil {
+			c.headers = make(http.Header)
+		}
+		c.headers.Set(key, value)
+	}
+}
+
+// Clone returns a copy of the client the options are applied to. Options passed to Clone only
+// affect the clone and are applied the same way as by NewClient, except that Clone never
+// authenticates: a clone with its own credentials authenticates on its first request.
+//
+// The clone shares the transport of the HTTP client (and therefore its connection pool and TLS
+// configuration), the rate limit and the caches with the original client. Options modifying the
+// transport (e.g. WithInsecure or WithExpectContinue) apply to a copy of it, so the clone gets its
+// own connection pool in this case. Headers, logger, timeout and all other settings are copied.
+//
+// The token is shared as well, unless an option changes how tokens are obtained: WithToken,
+// WithScope, WithSSOURL, WithTokenRefreshSkew and WithClock give the clone its own credentials
+// (using the username and password of the original), WithCredentialProvider its own provider.
+func (c *Client) Clone(opts ...ClientOption) (*Client, error) {
+	clone := *c
+	clone.headers = c.headers.Clone()
+	hc := *c.client
+	clone.client = &hc
+	clone.sharedTransport = true
+	clone.optionErr = nil
+
+	// reset to detect the options changing the credentials
+	clone.token = ""
+	clone.now = nil
+
+	for _, o := range opts {
+		o(&clone)
+	}
+
+	clockChanged := clone.now != nil
+	if !clockChanged {
+		clone.now = c.now
+	}
+
+	if clone.ownCredentials && (clone.token != "" || clockChanged || clone.scope != c.scope ||
+		clone.ssoURL != c.ssoURL || clone.refreshSkew != c.refreshSkew) {
+		clone.credentials = nil
+		clone.ownCredentials = false
+	}
+
+	err := clone.applyOptions()
+	if err != nil {
+		return nil, err
+	}
+
+	return &clone, nil
+}
@@ -0,0 +1,150 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestClone(t *testing.T) {
+	e := newTestEngine(t)
+	e.handle("/vms", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Authorization", r.Header.Get("Authorization"))
+		w.Header().Set("X-Tenant", r.Header.Get("X-Tenant"))
+		writeXML(w, http.StatusOK, "<vms/>")
+	})
+	c := e.client(t, WithHeader("X-Tenant", "a"))
+
+	clone, err := c.Clone(WithHeader("X-Tenant", "b"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for client, tenant := range map[*Client]string{c: "a", clone: "b"} {
+		resp, err := client.SendRaw("/vms", "GET", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := resp.Header.Get("X-Tenant"); got != tenant {
+			t.Errorf("expected tenant header %q, got %q", tenant, got)
+		}
+
+		if got := resp.Header.Get("X-Authorization"); got != "Bearer token-1" {
+			t.Errorf("expected the shared token, got %q", got)
+		}
+	}
+
+	if n := e.tokens(); n != 1 {
+		t.Fatalf("expected the clone to share the token, got %d token requests", n)
+	}
+}
+
+func TestCloneWithToken(t *testing.T) {
+	e := newTestEngine(t)
+	e.handle("/vms", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Authorization", r.Header.Get("Authorization"))
+		writeXML(w, http.StatusOK, "<vms/>")
+	})
+	c := e.client(t)
+
+	clone, err := c.Clone(WithToken("other"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for client, token := range map[*Client]string{c: "token-1", clone: "other"} {
+		resp, err := client.SendRaw("/vms", "GET", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := resp.Header.Get("X-Authorization"); got != "Bearer "+token {
+			t.Errorf("expected token %q, got %q", token, got)
+		}
+	}
+}
+
+func TestCloneWithTimeout(t *testing.T) {
+	e := newTestEngine(t)
+	e.handle("/vms", func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(300 * time.Millisecond)
+		writeXML(w, http.StatusOK, "<vms/>")
+	})
+	c := e.client(t)
+
+	clone, err := c.Clone(WithTimeout(50 * time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	_, err = clone.Get("/vms")
+	if err == nil {
+		t.Fatal("request of the clone did not time out")
+	}
+
+	if d := time.Since(start); d >= 300*time.Millisecond {
+		t.Fatalf("request of the clone took %s", d)
+	}
+
+	_, err = c.Get("/vms")
+	if err != nil {
+		t.Fatalf("timeout of the clone applies to the original: %v", err)
+	}
+}
+
+func TestCloneOptionError(t *testing.T) {
+	c, err := NewClient("https://engine/ovirt-engine/api", "", "", WithToken("token"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = c.Clone(WithCACertFile("/missing/ca.pem"))
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected the error of the option, got %v", err)
+	}
+}
+
+func TestCloneWithDebug(t *testing.T) {
+	c, err := NewClient("https://engine/ovirt-engine/api", "", "", WithToken("token"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clone, err := c.Clone(WithDebug())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if l := clone.logger.(*defaultLogger); !l.debug {
+		t.Fatal("WithDebug did not enable debug logging of the clone")
+	}
+
+	if l := c.logger.(*defaultLogger); l.debug {
+		t.Fatal("WithDebug of the clone enabled debug logging of the original")
+	}
+}
+
+func TestCloneWithInsecure(t *testing.T) {
+	c, err := NewClient("https://engine/ovirt-engine/api", "", "", WithToken("token"), WithTransportConfig(10, 5, 5, time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clone, err := c.Clone(WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg := c.client.Transport.(*http.Transport).TLSClientConfig; cfg != nil && cfg.InsecureSkipVerify {
+		t.Fatal("WithInsecure of the clone disabled certificate validation of the original")
+	}
+
+	tr := clone.client.Transport.(*http.Transport)
+	if !tr.TLSClientConfig.InsecureSkipVerify || tr.MaxIdleConnsPerHost != 5 {
+		t.Fatal("clone does not use a copy of the transport with WithInsecure applied")
+	}
+}
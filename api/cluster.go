@@ -0,0 +1,83 @@
+package api
+
+import (
+	"encoding/xml"
+	"fmt"
+)
+
+// Clusters is a collection of clusters as returned by the API
+type Clusters struct {
+	Clusters []Cluster `xml:"cluster"`
+}
+
+// Cluster represents a cluster of hosts
+type Cluster struct {
+	XMLName           xml.Name      `xml:"cluster"`
+	ID                string        `xml:"id,attr,omitempty"`
+	Href              string        `xml:"href,attr,omitempty"`
+	Name              string        `xml:"name,omitempty"`
+	Description       string        `xml:"description,omitempty"`
+	DataCenter        *Link         `xml:"data_center,omitempty"`
+	MACPool           *Link         `xml:"mac_pool,omitempty"`
+	MemoryPolicy      *MemoryPolicy `xml:"memory_policy,omitempty"`
+	Version           *Version      `xml:"version,omitempty"`
+	SupportedVersions []Version     `xml:"supported_versions>version,omitempty"`
+}
+
+// MemoryPolicy describes how memory of the hosts in a cluster is shared between VMs
+type MemoryPolicy struct {
+	OverCommit *MemoryOverCommit `xml:"over_commit,omitempty"`
+}
+
+// MemoryOverCommit is the percentage of physical memory VMs of a cluster may allocate
+type MemoryOverCommit struct {
+	Percent int `xml:"percent"`
+}
+
+const (
+	minMemoryOverCommit = 100
+	maxMemoryOverCommit = 400
+)
+
+// GetCluster retrieves a cluster by id
+func (c *Client) GetCluster(id string) (*Cluster, error) {
+	res := &Cluster{}
+	err := c.GetAndParse("/clusters/"+id, res)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// updateCluster sends a PUT containing only the fields set in changes
+func (c *Client) updateCluster(id string, changes *Cluster) error {
+	return c.SendObject("/clusters/"+id, "PUT", changes, &Cluster{})
+}
+
+// ClusterMemoryOverCommit returns the memory over commit percentage of a cluster
+func (c *Client) ClusterMemoryOverCommit(id string) (int, error) {
+	cl, err := c.GetCluster(id)
+	if err != nil {
+		return 0, err
+	}
+
+	if cl.MemoryPolicy == nil || cl.MemoryPolicy.OverCommit == nil {
+		return 0, fmt.Errorf("cluster %s does not report a memory over commit", id)
+	}
+
+	return cl.MemoryPolicy.OverCommit.Percent, nil
+}
+
+// SetClusterMemoryOverCommit sets the memory over commit percentage of a cluster (100 to 400)
+func (c *Client) SetClusterMemoryOverCommit(id string, percent int) error {
+	if percent < minMemoryOverCommit || percent > maxMemoryOverCommit {
+		return fmt.Errorf("memory over commit must be between %d and %d percent, got %d", minMemoryOverCommit, maxMemoryOverCommit, percent)
+	}
+
+	return c.updateCluster(id, &Cluster{
+		MemoryPolicy: &MemoryPolicy{
+			OverCommit: &MemoryOverCommit{Percent: percent},
+		},
+	})
+}
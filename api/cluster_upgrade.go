@@ -0,0 +1,85 @@
+package api
+
+import "fmt"
+
+// ClusterUpgradeCheck is the result of a pre-flight check for raising the compatibility version of a cluster
+type ClusterUpgradeCheck struct {
+	ClusterID string
+	Target    Version
+
+	// SupportedByCluster is false if the engine does not offer the target version for the cluster
+	SupportedByCluster bool
+
+	BlockingHosts []UpgradeBlocker
+	BlockingVMs   []UpgradeBlocker
+}
+
+// UpgradeBlocker is a host or VM preventing a cluster upgrade
+type UpgradeBlocker struct {
+	ID     string
+	Name   string
+	Reason string
+}
+
+// CanUpgrade returns true if nothing blocks the upgrade
+func (r *ClusterUpgradeCheck) CanUpgrade() bool {
+	return r.SupportedByCluster && len(r.BlockingHosts) == 0 && len(r.BlockingVMs) == 0
+}
+
+// vmStatusesBlockingUpgrade are states in which the engine rejects configuration changes of a VM
+var vmStatusesBlockingUpgrade = map[string]bool{
+	"image_locked":    true,
+	"migrating":       true,
+	"saving_state":    true,
+	"restoring_state": true,
+}
+
+// CheckClusterUpgrade checks whether the cluster can be raised to the target compatibility version.
+// Hosts block the upgrade if they are not up or do not support the version, VMs if their custom
+// compatibility version is pinned above the target or they are in a locked state.
+func (c *Client) CheckClusterUpgrade(clusterID string, target Version) (*ClusterUpgradeCheck, error) {
+	cl, err := c.GetCluster(clusterID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &ClusterUpgradeCheck{
+		ClusterID:          clusterID,
+		Target:             target,
+		SupportedByCluster: containsVersion(cl.SupportedVersions, target),
+	}
+
+	query := "cluster=" + quoteSearchValue(cl.Name)
+
+	hosts := &Hosts{}
+	err = c.GetAndParse(searchPath("/hosts", query), hosts)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, h := range hosts.Hosts {
+		switch {
+		case h.Status != "up":
+			res.BlockingHosts = append(res.BlockingHosts, UpgradeBlocker{ID: h.ID, Name: h.Name, Reason: "host is " + h.Status})
+		case len(h.SupportedVersions) > 0 && !containsVersion(h.SupportedVersions, target):
+			res.BlockingHosts = append(res.BlockingHosts, UpgradeBlocker{ID: h.ID, Name: h.Name, Reason: fmt.Sprintf("host does not support version %s", target)})
+		}
+	}
+
+	vms := &VMs{}
+	err = c.GetAndParse(searchPath("/vms", query), vms)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, vm := range vms.VMs {
+		switch {
+		case vmStatusesBlockingUpgrade[vm.Status]:
+			res.BlockingVMs = append(res.BlockingVMs, UpgradeBlocker{ID: vm.ID, Name: vm.Name, Reason: "vm is " + vm.Status})
+		case vm.CustomCompatibilityVersion != nil && vm.CustomCompatibilityVersion.Compare(target) > 0:
+			res.BlockingVMs = append(res.BlockingVMs, UpgradeBlocker{ID: vm.ID, Name: vm.Name, Reason: fmt.Sprintf("vm is pinned to version %s", vm.CustomCompatibilityVersion)})
+		}
+	}
+
+	return res, nil
+}
@@ -0,0 +1,49 @@
+package api
+
+import (
+	"net/url"
+	"strconv"
+)
+
+// GetCollection retrieves a collection (e.g. VMs) into v, a pointer to a collection struct, and
+// reports if the engine probably has more items: the collection was limited with the max
+// parameter of path and exactly max items were returned. Use Paginate to retrieve all items.
+func (c *Client) GetCollection(path string, v interface{}, opts ...RequestOption) (bool, error) {
+	// fail before sending the request if v is no collection
+	_, err := countItems(v)
+	if err != nil {
+		return false, err
+	}
+
+	err = c.GetAndParse(path, v, opts...)
+	if err != nil {
+		return false, err
+	}
+
+	max := requestedMax(path)
+	if max <= 0 {
+		return false, nil
+	}
+
+	n, err := countItems(v)
+	if err != nil {
+		return false, err
+	}
+
+	return n >= max, nil
+}
+
+// requestedMax returns the max parameter of path or 0 if there is none
+func requestedMax(path string) int {
+	u, err := url.Parse(path)
+	if err != nil {
+		return 0
+	}
+
+	max, err := strconv.Atoi(u.Query().Get("max"))
+	if err != nil {
+		return 0
+	}
+
+	return max
+}
@@ -0,0 +1,36 @@
+package api
+
+import "testing"
+
+func TestGetCollection(t *testing.T) {
+	e := newTestEngine(t)
+	e.handle("/vms", pagedVMs(5))
+	c := e.client(t)
+
+	tests := []struct {
+		path  string
+		items int
+		more  bool
+	}{
+		{"/vms?search=page%201&max=5", 5, true},
+		{"/vms?search=page%201&max=10", 5, false},
+		{"/vms?search=page%202&max=3", 2, false},
+		{"/vms?search=page%201&max=3", 3, true},
+	}
+	for _, tc := range tests {
+		vms := &VMs{}
+		more, err := c.GetCollection(tc.path, vms)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.path, err)
+		}
+
+		if len(vms.VMs) != tc.items || more != tc.more {
+			t.Errorf("%s: expected %d items and more %t, got %d and %t", tc.path, tc.items, tc.more, len(vms.VMs), more)
+		}
+	}
+
+	_, err := c.GetCollection("/vms?max=1", &VM{})
+	if err == nil {
+		t.Fatal("expected an error for a result which is no collection")
+	}
+}
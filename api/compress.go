@@ -0,0 +1,40 @@
+package api
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+)
+
+// WithRequestCompression gzip compresses request bodies of at least minSize bytes.
+// Only enable this if the engine (or the proxy in front of it) decompresses request bodies.
+func WithRequestCompression(minSize int) ClientOption {
+	return func(c *Client) {
+		c.compressMin = minSize
+	}
+}
+
+// encodeBody returns the reader to send for payload and the content encoding applied (if any)
+func (c *Client) encodeBody(payload []byte) (io.Reader, string, error) {
+	if payload == nil {
+		return nil, "", nil
+	}
+
+	if c.compressMin <= 0 || len(payload) < c.compressMin {
+		return bytes.NewReader(payload), "", nil
+	}
+
+	buf := &bytes.Buffer{}
+	w := gzip.NewWriter(buf)
+	_, err := w.Write(payload)
+	if err != nil {
+		return nil, "", err
+	}
+
+	err = w.Close()
+	if err != nil {
+		return nil, "", err
+	}
+
+	return buf, "gzip", nil
+}
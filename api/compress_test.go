@@ -0,0 +1,68 @@
+package api
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestRequestCompression(t *testing.T) {
+	e := newTestEngine(t)
+	var requests int32
+	var encodings, bodies []string
+	e.handle("/vms", func(w http.ResponseWriter, r *http.Request) {
+		var body io.Reader = r.Body
+		encoding := r.Header.Get("Content-Encoding")
+		if encoding == "gzip" {
+			zr, err := gzip.NewReader(r.Body)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			body = zr
+		}
+
+		b, err := io.ReadAll(body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		encodings = append(encodings, encoding)
+		bodies = append(bodies, string(b))
+
+		// reject the first token, so the compressed body has to be sent again
+		if atomic.AddInt32(&requests, 1) == 1 {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		writeXML(w, http.StatusCreated, `<vm id="123"/>`)
+	})
+	c := e.client(t, WithRequestCompression(1024))
+
+	large := "<vm><description>" + strings.Repeat("a", 2048) + "</description></vm>"
+	_, err := c.SendRequest("/vms", "POST", strings.NewReader(large))
+	if err != nil {
+		t.Fatalf("SendRequest: %v", err)
+	}
+
+	if len(bodies) != 2 {
+		t.Fatalf("expected the request to be repeated after reauthentication, got %d requests", len(bodies))
+	}
+	for i := range bodies {
+		if encodings[i] != "gzip" || bodies[i] != large {
+			t.Fatalf("expected request %d to be the gzip compressed body, got %q encoded %q", i+1, bodies[i], encodings[i])
+		}
+	}
+
+	small := `<vm><name>web01</name></vm>`
+	_, err = c.SendRequest("/vms", "POST", strings.NewReader(small))
+	if err != nil {
+		t.Fatalf("SendRequest: %v", err)
+	}
+	if encodings[2] != "" || bodies[2] != small {
+		t.Fatalf("expected a body below the minimum size to be sent uncompressed, got %q encoded %q", bodies[2], encodings[2])
+	}
+}
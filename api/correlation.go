@@ -0,0 +1,37 @@
+package api
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+)
+
+// WithCorrelationID stamps every request with a Correlation-Id header generated by gen
+// (RandomCorrelationID if nil). The engine logs the id with the operations it performs,
+// so failed requests can be looked up in engine.log.
+func WithCorrelationID(gen func() string) ClientOption {
+	return func(c *Client) {
+		if gen == nil {
+			gen = RandomCorrelationID
+		}
+		c.correlationID = gen
+	}
+}
+
+// CorrelationID sets the Correlation-Id header of a single request
+func CorrelationID(id string) RequestOption {
+	return func(req *http.Request) {
+		req.Header.Set(correlationIDHeader, id)
+	}
+}
+
+// RandomCorrelationID returns a random 128 bit id in hex encoding
+func RandomCorrelationID() string {
+	b := make([]byte, 16)
+	_, err := rand.Read(b)
+	if err != nil {
+		panic(err)
+	}
+
+	return hex.EncodeToString(b)
+}
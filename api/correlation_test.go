@@ -0,0 +1,37 @@
+package api
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWithCorrelationID(t *testing.T) {
+	e := newTestEngine(t)
+	hr := &headerRecorder{body: "<vms/>"}
+	e.handle("/vms", hr.ServeHTTP)
+	l := &testLogger{}
+	c := e.client(t, WithCorrelationID(nil), WithLogger(l))
+
+	ids := map[string]bool{}
+	for i := 0; i < 2; i++ {
+		resp, err := c.SendRaw("/vms", "GET", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		id := resp.CorrelationID
+		if id == "" || hr.last().Get("Correlation-Id") != id {
+			t.Fatalf("expected the correlation id %q to be sent, got %q", id, hr.last().Get("Correlation-Id"))
+		}
+
+		if !strings.Contains(l.output(), "(correlation id: "+id+")") {
+			t.Fatalf("correlation id %s was not logged:\n%s", id, l.output())
+		}
+
+		ids[id] = true
+	}
+
+	if len(ids) != 2 {
+		t.Fatalf("expected unique correlation ids, got %v", ids)
+	}
+}
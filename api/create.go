@@ -0,0 +1,131 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// Create posts obj (see SendObject) to the collection at path, unmarshals the response into res
+// (if not nil) and returns the id of the new entity. If the engine only accepted the request
+// (202 with an action), the entity contained in the action is unmarshaled instead. The id is taken
+// from the returned entity, falling back to the Location header.
+func (c *Client) Create(path string, obj, res interface{}, opts ...RequestOption) (string, error) {
+	resp, err := c.send(context.Background(), path, "POST", obj, opts)
+	if err != nil {
+		return "", err
+	}
+
+	name, id := rootElement(resp.Body)
+	if name == "action" {
+		// the entity is created asynchronously, the action references it
+		id, err = actionEntity(resp.Body, res)
+		if err != nil {
+			return "", err
+		}
+		if id == "" {
+			id = locationID(resp.Header.Get("Location"))
+		}
+
+		return id, nil
+	}
+
+	err = decodeResponse(resp, res)
+	if err != nil {
+		return "", err
+	}
+
+	if id == "" {
+		id = locationID(resp.Header.Get("Location"))
+	}
+
+	return id, nil
+}
+
+// rootElement returns the name and the id attribute of the root element of an XML document
+func rootElement(body []byte) (string, string) {
+	d := xml.NewDecoder(bytes.NewReader(body))
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return "", ""
+		}
+
+		se, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		for _, a := range se.Attr {
+			if a.Name.Local == "id" {
+				return se.Name.Local, a.Value
+			}
+		}
+
+		return se.Name.Local, ""
+	}
+}
+
+// actionEntity unmarshals the entity contained in an action (e.g. <action><vm id="123">...</vm></action>)
+// into res (if not nil) and returns its id. It returns an empty id if the action contains no entity.
+func actionEntity(body []byte, res interface{}) (string, error) {
+	d := xml.NewDecoder(bytes.NewReader(body))
+	depth := 0
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return "", nil
+		}
+
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			depth++
+			if depth != 2 || tok.Name.Local == "job" {
+				continue
+			}
+
+			id := ""
+			for _, a := range tok.Attr {
+				if a.Name.Local == "id" {
+					id = a.Value
+				}
+			}
+			if id == "" {
+				continue
+			}
+
+			if res != nil {
+				err = d.DecodeElement(res, &tok)
+				if err != nil {
+					return "", err
+				}
+			}
+
+			return id, nil
+		case xml.EndElement:
+			depth--
+		}
+	}
+}
+
+// locationID returns the last path segment of a Location header
+func locationID(location string) string {
+	if location == "" {
+		return ""
+	}
+
+	u, err := url.Parse(location)
+	if err != nil {
+		return ""
+	}
+
+	id := path.Base(strings.TrimRight(u.Path, "/"))
+	if id == "." || id == "/" {
+		return ""
+	}
+
+	return id
+}
@@ -0,0 +1,53 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestCreate(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		location string
+		body     string
+		id       string
+		vmName   string
+	}{
+		{"created", http.StatusCreated, "", `<vm href="/ovirt-engine/api/vms/123" id="123"><name>web01</name><status>down</status></vm>`, "123", "web01"},
+		{"created with location", http.StatusCreated, "/ovirt-engine/api/vms/456", `<vm><name>web01</name></vm>`, "456", "web01"},
+		{"accepted", http.StatusAccepted, "/ovirt-engine/api/jobs/j1",
+			`<action><job href="/ovirt-engine/api/jobs/j1" id="j1"/><status>pending</status><vm href="/ovirt-engine/api/vms/789" id="789"><name>web01</name></vm></action>`, "789", "web01"},
+		{"accepted with location", http.StatusAccepted, "https://engine/ovirt-engine/api/vms/321/", `<action><status>pending</status></action>`, "321", ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			e := newTestEngine(t)
+			e.handle("/vms", func(w http.ResponseWriter, r *http.Request) {
+				if tc.location != "" {
+					w.Header().Set("Location", tc.location)
+				}
+				writeXML(w, tc.status, tc.body)
+			})
+			c := e.client(t)
+
+			vm := &VM{}
+			id, err := c.Create("/vms", &VM{Name: "web01"}, vm)
+			if err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+			if id != tc.id {
+				t.Fatalf("expected id %q, got %q", tc.id, id)
+			}
+			if vm.Name != tc.vmName {
+				t.Fatalf("expected the entity to be parsed, got %+v", vm)
+			}
+
+			id, err = c.Create("/vms", &VM{Name: "web01"}, nil)
+			if err != nil || id != tc.id {
+				t.Fatalf("Create without result: %q, %v", id, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,219 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrNoCredentials is returned if a new token is required but no username was configured
+var ErrNoCredentials = errors.New("no credentials configured to request a new token")
+
+// ErrReauthFailed is returned if a request was rejected again after a successful reauthentication,
+// so the problem is not an expired token (e.g. clock skew or missing permissions). It wraps the APIError.
+var ErrReauthFailed = errors.New("request rejected after reauthentication")
+
+// CredentialProvider supplies SSO tokens and can be shared by multiple clients using the same account.
+// When the token is rejected by the engine, all clients ask the provider for a new one and only a
+// single request is sent to the SSO server.
+type CredentialProvider interface {
+	// Token returns the current token, fetching one if none is cached yet
+	Token(ctx context.Context) (string, error)
+
+	// Refresh replaces the rejected token. If the cached token already differs from rejected,
+	// another client refreshed it in the meantime and the cached token is returned.
+	// An empty rejected token always requests a new token.
+	Refresh(ctx context.Context, rejected string) (string, error)
+}
+
+// WithTokenRefreshSkew sets how long before its expiry (as reported by the SSO server) the token
+// is replaced, so requests are not rejected because the token just expired. Defaults to 30 seconds.
+// Ignored if WithCredentialProvider is used.
+func WithTokenRefreshSkew(d time.Duration) ClientOption {
+	return func(c *Client) {
+		c.refreshSkew = d
+	}
+}
+
+// WithCredentialProvider makes the client obtain its tokens from the (shared) provider
+// instead of authenticating with its own username and password
+func WithCredentialProvider(p CredentialProvider) ClientOption {
+	return func(c *Client) {
+		c.credentials = p
+		c.ownCredentials = false
+	}
+}
+
+// NewCredentialProvider returns a provider caching the tokens retrieved by fetch.
+// Concurrent refreshes are coalesced into a single call of fetch.
+func NewCredentialProvider(fetch func(ctx context.Context) (string, error)) CredentialProvider {
+	return &sharedCredentials{
+		fetch: func(ctx context.Context) (string, time.Duration, error) {
+			token, err := fetch(ctx)
+			return token, 0, err
+		},
+	}
+}
+
+// NewPasswordCredentialProvider returns a provider authenticating with username and password
+// against the SSO server of the engine at apiURL. httpClient may be nil to use http.DefaultClient.
+func NewPasswordCredentialProvider(apiURL, username, password string, httpClient *http.Client) CredentialProvider {
+	return newPasswordCredentialProvider(ssoTokenURL(apiURL), username, password, defaultScope, httpClient)
+}
+
+// newPasswordCredentialProvider returns a provider requesting tokens from the SSO token endpoint tokenURL
+func newPasswordCredentialProvider(tokenURL, username, password, scope string, httpClient *http.Client) *sharedCredentials {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	return &sharedCredentials{
+		fetch: func(ctx context.Context) (string, time.Duration, error) {
+			if username == "" {
+				return "", 0, ErrNoCredentials
+			}
+
+			return requestToken(ctx, httpClient, tokenURL, username, password, scope)
+		},
+		skew: defaultTokenRefreshSkew,
+	}
+}
+
+// defaultTokenRefreshSkew is how long before its expiry a token is replaced
+const defaultTokenRefreshSkew = 30 * time.Second
+
+type sharedCredentials struct {
+	// fetch returns a new token and its lifetime (0 if unknown)
+	fetch func(ctx context.Context) (string, time.Duration, error)
+	skew  time.Duration
+	now   func() time.Time
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+	call    *tokenCall
+}
+
+// tokenCall is a fetch in progress other callers can wait for
+type tokenCall struct {
+	done     chan struct{}
+	token    string
+	lifetime time.Duration
+	err      error
+}
+
+func (s *sharedCredentials) setToken(token string) {
+	s.mu.Lock()
+	s.token = token
+	s.expires = time.Time{}
+	s.mu.Unlock()
+}
+
+// expiring reports if the cached token expires within the refresh skew. s.mu must be held.
+func (s *sharedCredentials) expiring() bool {
+	return !s.expires.IsZero() && !s.clock().Add(s.skew).Before(s.expires)
+}
+
+func (s *sharedCredentials) clock() time.Time {
+	if s.now == nil {
+		return time.Now()
+	}
+
+	return s.now()
+}
+
+func (s *sharedCredentials) cachedToken() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.token
+}
+
+// clearToken forgets the token unless it was replaced in the meantime
+func (s *sharedCredentials) clearToken(token string) {
+	s.mu.Lock()
+	if s.token == token {
+		s.token = ""
+	}
+	s.mu.Unlock()
+}
+
+func (s *sharedCredentials) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	token := s.token
+	expiring := s.expiring()
+	s.mu.Unlock()
+
+	if token != "" && !expiring {
+		return token, nil
+	}
+
+	return s.Refresh(ctx, token)
+}
+
+func (s *sharedCredentials) Refresh(ctx context.Context, rejected string) (string, error) {
+	s.mu.Lock()
+	if rejected != "" && s.token != "" && s.token != rejected && !s.expiring() {
+		token := s.token
+		s.mu.Unlock()
+		return token, nil
+	}
+
+	call := s.call
+	if call == nil {
+		call = &tokenCall{done: make(chan struct{})}
+		s.call = call
+		go s.run(call)
+	}
+	s.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return "", ctx.Err()
+	case <-call.done:
+		return call.token, call.err
+	}
+}
+
+// run fetches the token without the context of the caller, so a caller giving up
+// does not fail the refresh for everybody else waiting for it
+func (s *sharedCredentials) run(call *tokenCall) {
+	call.token, call.lifetime, call.err = s.fetch(context.Background())
+
+	s.mu.Lock()
+	if call.err == nil {
+		s.token = call.token
+		s.expires = time.Time{}
+		if call.lifetime > 0 {
+			s.expires = s.clock().Add(call.lifetime)
+		}
+	}
+	s.call = nil
+	s.mu.Unlock()
+
+	close(call.done)
+}
+
+// jsonSeconds is a number of seconds the SSO server sends as JSON number or string
+type jsonSeconds int64
+
+func (s *jsonSeconds) UnmarshalJSON(b []byte) error {
+	str := strings.Trim(string(b), `"`)
+	if str == "" || str == "null" {
+		*s = 0
+		return nil
+	}
+
+	n, err := strconv.ParseInt(str, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid number of seconds %s: %w", b, err)
+	}
+
+	*s = jsonSeconds(n)
+	return nil
+}
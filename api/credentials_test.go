@@ -0,0 +1,112 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestProactiveRefresh(t *testing.T) {
+	e := newTestEngine(t)
+	issued := e.handleExpiringTokens("/expiring/sso/oauth/token", time.Hour)
+
+	var rejected int32
+	var auth atomic.Value
+	e.handle("/vms", func(w http.ResponseWriter, r *http.Request) {
+		auth.Store(r.Header.Get("Authorization"))
+		if r.Header.Get("Authorization") != "Bearer expiring-"+strconv.Itoa(int(atomic.LoadInt32(issued))) {
+			atomic.AddInt32(&rejected, 1)
+			writeXML(w, http.StatusUnauthorized, "")
+			return
+		}
+		writeXML(w, http.StatusOK, "<vms/>")
+	})
+
+	clock := newFakeClock()
+	c := e.client(t, WithClock(clock.now), WithSSOURL(e.URL+"/expiring/sso/oauth/token"))
+
+	_, err := c.Get("/vms")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if n := atomic.LoadInt32(issued); n != 1 {
+		t.Fatalf("expected a single token, got %d", n)
+	}
+
+	// still valid for longer than the skew
+	clock.advance(time.Hour - time.Minute)
+	_, err = c.Get("/vms")
+	if err != nil || atomic.LoadInt32(issued) != 1 {
+		t.Fatalf("expected the token to be reused, got %d tokens (%v)", atomic.LoadInt32(issued), err)
+	}
+
+	// within the default skew of 30 seconds
+	clock.advance(45 * time.Second)
+	_, err = c.Get("/vms")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if n := atomic.LoadInt32(issued); n != 2 {
+		t.Fatalf("expected the token to be refreshed before the request, got %d tokens", n)
+	}
+	if got := auth.Load(); got != "Bearer expiring-2" {
+		t.Fatalf("expected the request to be sent with the new token, got %v", got)
+	}
+	if n := atomic.LoadInt32(&rejected); n != 0 {
+		t.Fatalf("expected no request to be rejected, got %d", n)
+	}
+}
+
+func TestReauthFailed(t *testing.T) {
+	e := newTestEngine(t)
+	var requests int32
+	e.handle("/vms", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		writeXML(w, http.StatusUnauthorized, `<fault><reason>Unauthorized</reason></fault>`)
+	})
+	c := e.client(t)
+
+	_, err := c.Get("/vms")
+	if !errors.Is(err, ErrReauthFailed) {
+		t.Fatalf("expected ErrReauthFailed, got %v", err)
+	}
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected the 401 to be wrapped, got %v", err)
+	}
+
+	// the request is repeated once after the reauthentication
+	if n := atomic.LoadInt32(&requests); n != 2 {
+		t.Fatalf("expected 2 requests, got %d", n)
+	}
+	if n := e.tokens(); n != 2 {
+		t.Fatalf("expected a single reauthentication, got %d token requests", n)
+	}
+}
+
+func TestWithoutAutoReauth(t *testing.T) {
+	e := newTestEngine(t)
+	var requests int32
+	e.handle("/vms", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		writeXML(w, http.StatusUnauthorized, "")
+	})
+	c := e.client(t, WithoutAutoReauth())
+
+	_, err := c.Get("/vms")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized || errors.Is(err, ErrReauthFailed) {
+		t.Fatalf("expected the 401 to be returned, got %v", err)
+	}
+
+	if n := atomic.LoadInt32(&requests); n != 1 {
+		t.Fatalf("expected a single request, got %d", n)
+	}
+	if n := e.tokens(); n != 1 {
+		t.Fatalf("expected no reauthentication, got %d token requests", n)
+	}
+}
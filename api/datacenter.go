@@ -0,0 +1,31 @@
+package api
+
+import "encoding/xml"
+
+// DataCenters is a collection of data centers as returned by the API
+type DataCenters struct {
+	DataCenters []DataCenter `xml:"data_center"`
+}
+
+// DataCenter represents a data center
+type DataCenter struct {
+	XMLName     xml.Name `xml:"data_center"`
+	ID          string   `xml:"id,attr,omitempty"`
+	Href        string   `xml:"href,attr,omitempty"`
+	Name        string   `xml:"name,omitempty"`
+	Description string   `xml:"description,omitempty"`
+	Status      string   `xml:"status,omitempty"`
+	Local       bool     `xml:"local,omitempty"`
+	Version     *Version `xml:"version,omitempty"`
+}
+
+// GetDataCenter retrieves a data center by id
+func (c *Client) GetDataCenter(id string) (*DataCenter, error) {
+	res := &DataCenter{}
+	err := c.GetAndParse("/datacenters/"+id, res)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
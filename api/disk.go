@@ -0,0 +1,114 @@
+package api
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"time"
+)
+
+// Disks is a collection of disks as returned by the API
+type Disks struct {
+	Disks []Disk `xml:"disk"`
+}
+
+// Disk represents a virtual disk
+type Disk struct {
+	XMLName         xml.Name `xml:"disk"`
+	ID              string   `xml:"id,attr,omitempty"`
+	Href            string   `xml:"href,attr,omitempty"`
+	Name            string   `xml:"name,omitempty"`
+	Alias           string   `xml:"alias,omitempty"`
+	Description     string   `xml:"description,omitempty"`
+	Status          string   `xml:"status,omitempty"`
+	Format          string   `xml:"format,omitempty"`
+	Sparse          bool     `xml:"sparse,omitempty"`
+	ProvisionedSize int64    `xml:"provisioned_size,omitempty"`
+	ActualSize      int64    `xml:"actual_size,omitempty"`
+	TotalSize       int64    `xml:"total_size,omitempty"`
+	StorageDomains  []Link   `xml:"storage_domains>storage_domain,omitempty"`
+}
+
+// DiskAttachments is a collection of disk attachments as returned by the API
+type DiskAttachments struct {
+	DiskAttachments []DiskAttachment `xml:"disk_attachment"`
+}
+
+// DiskAttachment attaches a disk to a VM
+type DiskAttachment struct {
+	XMLName   xml.Name `xml:"disk_attachment"`
+	ID        string   `xml:"id,attr,omitempty"`
+	Href      string   `xml:"href,attr,omitempty"`
+	Active    bool     `xml:"active,omitempty"`
+	Bootable  bool     `xml:"bootable,omitempty"`
+	Interface string   `xml:"interface,omitempty"`
+	Disk      *Link    `xml:"disk,omitempty"`
+	VM        *Link    `xml:"vm,omitempty"`
+}
+
+// VMDiskAttachments retrieves the disk attachments of a VM
+func (c *Client) VMDiskAttachments(vmID string) ([]DiskAttachment, error) {
+	res := &DiskAttachments{}
+	err := c.GetAndParse("/vms/"+vmID+"/diskattachments", res)
+	if err != nil {
+		return nil, err
+	}
+
+	return res.DiskAttachments, nil
+}
+
+// GetDisk retrieves a disk by id
+func (c *Client) GetDisk(id string) (*Disk, error) {
+	res := &Disk{}
+	err := c.GetAndParse("/disks/"+id, res)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// CreateDisk creates a floating disk. The disk has to reference the storage domain to create it on.
+func (c *Client) CreateDisk(d *Disk) (*Disk, error) {
+	res := &Disk{}
+	err := c.SendObject("/disks", "POST", d, res)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// WaitForDiskStatus polls the disk until it reaches the target status (e.g. ok) or the timeout
+// elapses. It returns the last status seen.
+func (c *Client) WaitForDiskStatus(id, target string, timeout time.Duration) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	return c.WaitForDiskStatusContext(ctx, id, target)
+}
+
+// WaitForDiskStatusContext polls the disk until it reaches the target status or ctx is done.
+// It returns the last status seen.
+func (c *Client) WaitForDiskStatusContext(ctx context.Context, id, target string) (string, error) {
+	return waitForStatus(ctx, "disk "+id, target, func(ctx context.Context) (string, error) {
+		d := &Disk{}
+		err := c.GetAndParseContext(ctx, "/disks/"+id, d)
+		if err == nil && d.Status == "illegal" {
+			err = fmt.Errorf("disk %s became illegal", id)
+		}
+
+		return d.Status, err
+	})
+}
+
+// AttachDisk attaches an existing disk to a VM. The attachment has to reference the disk.
+func (c *Client) AttachDisk(vmID string, a *DiskAttachment) (*DiskAttachment, error) {
+	res := &DiskAttachment{}
+	err := c.SendObject("/vms/"+vmID+"/diskattachments", "POST", a, res)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
@@ -0,0 +1,83 @@
+package api
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DiskSpec describes a disk to create and how to attach it
+type DiskSpec struct {
+	Disk       *Disk
+	Attachment *DiskAttachment
+}
+
+// DiskResult is the outcome for a single DiskSpec. Disk is set once the disk was created,
+// so partially provisioned disks can be cleaned up if Err is set.
+type DiskResult struct {
+	Disk       *Disk
+	Attachment *DiskAttachment
+	Err        error
+}
+
+// CreateVMDisks creates the disks concurrently (at most concurrency at a time), waits for all of
+// them to become ok and attaches them to the VM afterwards. The results have the order of specs.
+// An error is returned if any disk failed, the results tell which ones.
+func (c *Client) CreateVMDisks(vmID string, specs []DiskSpec, concurrency int, timeout time.Duration) ([]DiskResult, error) {
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	results := make([]DiskResult, len(specs))
+	sem := make(chan struct{}, concurrency)
+	wg := sync.WaitGroup{}
+
+	for i, spec := range specs {
+		wg.Add(1)
+		sem <- struct{}{}
+
+		go func(r *DiskResult, spec DiskSpec) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			r.Disk, r.Err = c.CreateDisk(spec.Disk)
+			if r.Err != nil {
+				return
+			}
+
+			_, r.Err = c.WaitForDiskStatus(r.Disk.ID, "ok", timeout)
+		}(&results[i], spec)
+	}
+	wg.Wait()
+
+	failed := 0
+	for _, r := range results {
+		if r.Err != nil {
+			failed++
+		}
+	}
+
+	// disks are only attached if all were created, so a failed provisioning leaves no half configured VM
+	if failed == 0 {
+		for i, spec := range specs {
+			a := DiskAttachment{Active: true}
+			if spec.Attachment != nil {
+				a = *spec.Attachment
+			}
+			a.Disk = &Link{ID: results[i].Disk.ID}
+
+			results[i].Attachment, results[i].Err = c.AttachDisk(vmID, &a)
+			if results[i].Err != nil {
+				failed++
+			}
+		}
+	}
+
+	if failed > 0 {
+		return results, fmt.Errorf("%d of %d disks failed", failed, len(specs))
+	}
+
+	return results, nil
+}
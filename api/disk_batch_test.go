@@ -0,0 +1,146 @@
+package api
+
+import (
+	"encoding/xml"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeDisks serves the disk creation and the disk attachments of vm1. Disks named "bad" are
+// rejected and disks named "stuck" never become ok.
+type fakeDisks struct {
+	inFlight, maxInFlight int32
+	attached              int32
+}
+
+func newFakeDisks(t *testing.T, e *testEngine) *fakeDisks {
+	f := &fakeDisks{}
+	setPollInterval(t, time.Millisecond)
+
+	e.handle("/disks", func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&f.inFlight, 1)
+		defer atomic.AddInt32(&f.inFlight, -1)
+		for {
+			m := atomic.LoadInt32(&f.maxInFlight)
+			if n <= m || atomic.CompareAndSwapInt32(&f.maxInFlight, m, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+
+		d := &Disk{}
+		xml.NewDecoder(r.Body).Decode(d)
+		if d.Alias == "bad" {
+			writeXML(w, http.StatusBadRequest, "<fault><reason>Operation Failed</reason><detail>[Invalid disk]</detail></fault>")
+			return
+		}
+
+		writeXML(w, http.StatusCreated, `<disk id="`+d.Alias+`"><alias>`+d.Alias+`</alias><status>locked</status></disk>`)
+	})
+	e.handle("/disks/", func(w http.ResponseWriter, r *http.Request) {
+		id := strings.TrimPrefix(r.URL.Path, testAPIPath+"/disks/")
+		status := "ok"
+		if id == "stuck" {
+			status = "locked"
+		}
+		writeXML(w, http.StatusOK, `<disk id="`+id+`"><status>`+status+`</status></disk>`)
+	})
+	e.handle("/vms/vm1/diskattachments", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&f.attached, 1)
+		a := &DiskAttachment{}
+		xml.NewDecoder(r.Body).Decode(a)
+		writeXML(w, http.StatusCreated, `<disk_attachment id="`+a.Disk.ID+`"><disk id="`+a.Disk.ID+`"/></disk_attachment>`)
+	})
+
+	return f
+}
+
+func diskSpecs(aliases ...string) []DiskSpec {
+	specs := []DiskSpec{}
+	for _, a := range aliases {
+		specs = append(specs, DiskSpec{Disk: &Disk{Alias: a, ProvisionedSize: 1 << 30}})
+	}
+
+	return specs
+}
+
+func TestCreateVMDisks(t *testing.T) {
+	e := newTestEngine(t)
+	f := newFakeDisks(t, e)
+	c := e.client(t)
+
+	aliases := []string{"d1", "d2", "d3", "d4", "d5", "d6"}
+	results, err := c.CreateVMDisks("vm1", diskSpecs(aliases...), 2, time.Minute)
+	if err != nil {
+		t.Fatalf("CreateVMDisks: %v", err)
+	}
+
+	if got := atomic.LoadInt32(&f.maxInFlight); got != 2 {
+		t.Fatalf("expected 2 disks to be created at a time, got %d", got)
+	}
+	if n := atomic.LoadInt32(&f.attached); n != int32(len(aliases)) {
+		t.Fatalf("expected %d attachments, got %d", len(aliases), n)
+	}
+	for i, r := range results {
+		if r.Err != nil || r.Disk == nil || r.Disk.ID != aliases[i] || r.Attachment == nil || r.Attachment.ID != aliases[i] {
+			t.Fatalf("unexpected result %d: %+v", i, r)
+		}
+	}
+}
+
+func TestCreateVMDisksFailure(t *testing.T) {
+	e := newTestEngine(t)
+	f := newFakeDisks(t, e)
+	c := e.client(t)
+
+	results, err := c.CreateVMDisks("vm1", diskSpecs("d1", "bad", "d3"), 3, time.Minute)
+	if err == nil || !strings.Contains(err.Error(), "1 of 3 disks failed") {
+		t.Fatalf("expected a single failed disk, got %v", err)
+	}
+
+	if n := atomic.LoadInt32(&f.attached); n != 0 {
+		t.Fatalf("expected no disk to be attached, got %d attachments", n)
+	}
+	if len(results) != 3 {
+		t.Fatalf("expected a result per disk, got %d", len(results))
+	}
+	if !isStatus(results[1].Err, http.StatusBadRequest) || results[1].Disk != nil {
+		t.Fatalf("expected the bad disk to be rejected, got %+v", results[1])
+	}
+
+	// the created disks are reported so they can be cleaned up
+	for _, i := range []int{0, 2} {
+		r := results[i]
+		if r.Err != nil || r.Disk == nil || r.Attachment != nil {
+			t.Fatalf("expected disk %d to be created but not attached, got %+v", i, r)
+		}
+	}
+}
+
+func TestCreateVMDisksTimeout(t *testing.T) {
+	e := newTestEngine(t)
+	f := newFakeDisks(t, e)
+	c := e.client(t)
+
+	start := time.Now()
+	results, err := c.CreateVMDisks("vm1", diskSpecs("d1", "stuck"), 2, 100*time.Millisecond)
+	if err == nil {
+		t.Fatal("expected the stuck disk to fail")
+	}
+	if d := time.Since(start); d > 2*time.Second {
+		t.Fatalf("expected the wait to stop after the timeout, took %s", d)
+	}
+
+	if results[0].Err != nil {
+		t.Fatalf("expected the first disk to become ok, got %v", results[0].Err)
+	}
+	if got := results[1].Err; got == nil || !strings.Contains(got.Error(), "disk stuck did not become ok (last status: locked)") {
+		t.Fatalf("expected a timeout of the stuck disk, got %v", got)
+	}
+	if n := atomic.LoadInt32(&f.attached); n != 0 {
+		t.Fatalf("expected no disk to be attached, got %d attachments", n)
+	}
+}
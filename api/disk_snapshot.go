@@ -0,0 +1,49 @@
+package api
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+// DiskSnapshots is a collection of disk snapshots as returned by the API
+type DiskSnapshots struct {
+	DiskSnapshots []DiskSnapshot `xml:"disk_snapshot"`
+}
+
+// DiskSnapshot is a point in time volume of a disk stored on a storage domain
+type DiskSnapshot struct {
+	XMLName         xml.Name  `xml:"disk_snapshot"`
+	ID              string    `xml:"id,attr,omitempty"`
+	Href            string    `xml:"href,attr,omitempty"`
+	Alias           string    `xml:"alias,omitempty"`
+	Status          string    `xml:"status,omitempty"`
+	ActualSize      int64     `xml:"actual_size,omitempty"`
+	ProvisionedSize int64     `xml:"provisioned_size,omitempty"`
+	CreationTime    time.Time `xml:"creation_time,omitempty"`
+	Disk            *Link     `xml:"disk,omitempty"`
+	Parent          *Link     `xml:"parent,omitempty"`
+	Snapshot        *Link     `xml:"snapshot,omitempty"`
+	StorageDomain   *Link     `xml:"storage_domain,omitempty"`
+}
+
+// ListDiskSnapshots retrieves the disk snapshots stored on a storage domain
+func (c *Client) ListDiskSnapshots(sdID string) ([]DiskSnapshot, error) {
+	res := &DiskSnapshots{}
+	err := c.GetAndParse("/storagedomains/"+sdID+"/disksnapshots", res)
+	if err != nil {
+		return nil, err
+	}
+
+	return res.DiskSnapshots, nil
+}
+
+// GetDiskSnapshot retrieves a disk snapshot stored on a storage domain
+func (c *Client) GetDiskSnapshot(sdID, id string) (*DiskSnapshot, error) {
+	res := &DiskSnapshot{}
+	err := c.GetAndParse("/storagedomains/"+sdID+"/disksnapshots/"+id, res)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
@@ -0,0 +1,55 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+)
+
+// vmDiskAttachments is a diskattachments sub collection as returned by the engine
+const vmDiskAttachments = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<disk_attachments>
+  <disk_attachment href="/ovirt-engine/api/vms/1/diskattachments/d1" id="d1">
+    <active>true</active>
+    <bootable>true</bootable>
+    <interface>virtio_scsi</interface>
+    <logical_name>/dev/sda</logical_name>
+    <pass_discard>false</pass_discard>
+    <read_only>false</read_only>
+    <uses_scsi_reservation>false</uses_scsi_reservation>
+    <disk href="/ovirt-engine/api/disks/d1" id="d1"/>
+    <vm href="/ovirt-engine/api/vms/1" id="1"/>
+  </disk_attachment>
+  <disk_attachment href="/ovirt-engine/api/vms/1/diskattachments/d2" id="d2">
+    <active>false</active>
+    <bootable>false</bootable>
+    <interface>virtio</interface>
+    <disk href="/ovirt-engine/api/disks/d2" id="d2"/>
+    <vm href="/ovirt-engine/api/vms/1" id="1"/>
+  </disk_attachment>
+</disk_attachments>`
+
+func TestVMDiskAttachments(t *testing.T) {
+	e := newTestEngine(t)
+	e.handle("/vms/1/diskattachments", func(w http.ResponseWriter, r *http.Request) {
+		writeXML(w, http.StatusOK, vmDiskAttachments)
+	})
+	c := e.client(t)
+
+	das, err := c.VMDiskAttachments("1")
+	if err != nil {
+		t.Fatalf("VMDiskAttachments: %v", err)
+	}
+	if len(das) != 2 {
+		t.Fatalf("expected 2 disk attachments, got %d", len(das))
+	}
+
+	da := das[0]
+	if da.ID != "d1" || !da.Active || !da.Bootable || da.Interface != "virtio_scsi" || da.Disk == nil || da.Disk.ID != "d1" || da.VM == nil || da.VM.ID != "1" {
+		t.Fatalf("unexpected disk attachment %+v", da)
+	}
+
+	da = das[1]
+	if da.Active || da.Bootable || da.Interface != "virtio" || da.Disk == nil || da.Disk.Href != "/ovirt-engine/api/disks/d2" {
+		t.Fatalf("unexpected disk attachment %+v", da)
+	}
+}
@@ -0,0 +1,59 @@
+package api
+
+// DiskUsage compares the provisioned size of a disk with the storage it actually consumes
+type DiskUsage struct {
+	DiskID string
+	Name   string
+
+	// ProvisionedSize is the virtual size visible to the guest
+	ProvisionedSize int64
+
+	// ActualSize is the space allocated by the active volume
+	ActualSize int64
+
+	// SnapshotSize is the space allocated by the snapshots of the disk
+	SnapshotSize int64
+
+	// TotalSize is the space allocated by the disk including all snapshots
+	TotalSize int64
+}
+
+// VMDiskUsage reports the provisioned and allocated sizes for each disk attached to a VM
+func (c *Client) VMDiskUsage(vmID string) ([]DiskUsage, error) {
+	attachments := &DiskAttachments{}
+	err := c.GetAndParse("/vms/"+vmID+"/diskattachments", attachments)
+	if err != nil {
+		return nil, err
+	}
+
+	usage := make([]DiskUsage, 0, len(attachments.DiskAttachments))
+	for _, a := range attachments.DiskAttachments {
+		if a.Disk == nil {
+			continue
+		}
+
+		d, err := c.GetDisk(a.Disk.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		u := DiskUsage{
+			DiskID:          d.ID,
+			Name:            d.Alias,
+			ProvisionedSize: d.ProvisionedSize,
+			ActualSize:      d.ActualSize,
+			TotalSize:       d.TotalSize,
+		}
+		if u.Name == "" {
+			u.Name = d.Name
+		}
+		if u.TotalSize < u.ActualSize {
+			u.TotalSize = u.ActualSize
+		}
+		u.SnapshotSize = u.TotalSize - u.ActualSize
+
+		usage = append(usage, u)
+	}
+
+	return usage, nil
+}
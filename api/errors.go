@@ -0,0 +1,97 @@
+package api
+
+import (
+	"encoding/xml"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// correlationIDHeader is the header used to correlate requests with the engine log
+const correlationIDHeader = "Correlation-Id"
+
+// APIError is returned for requests the engine answered with an error status
+type APIError struct {
+	StatusCode int
+	Status     string
+	Header     http.Header
+
+	// CorrelationID is the correlation id sent with the request (if any)
+	CorrelationID string
+
+	// ResponseCorrelationID is the correlation id returned by the engine (if any)
+	ResponseCorrelationID string
+
+	// Fault is the fault description returned by the engine (if the body could be parsed)
+	Fault *Fault
+}
+
+// Fault describes why the engine rejected a request
+type Fault struct {
+	XMLName xml.Name `xml:"fault"`
+	Reason  string   `xml:"reason"`
+	Detail  string   `xml:"detail"`
+}
+
+// Error implements error interface
+func (f *Fault) Error() string {
+	if f.Detail == "" {
+		return f.Reason
+	}
+
+	return f.Reason + ": " + f.Detail
+}
+
+// parseFault returns the fault contained in body or nil if body is no fault document
+func parseFault(body []byte) *Fault {
+	f := &Fault{}
+	err := xml.Unmarshal(body, f)
+	if err != nil || (f.Reason == "" && f.Detail == "") {
+		return nil
+	}
+
+	return f
+}
+
+// Unwrap returns the fault, so it can be retrieved with errors.As
+func (e *APIError) Unwrap() error {
+	if e.Fault == nil {
+		return nil
+	}
+
+	return e.Fault
+}
+
+// Error implements error interface
+func (e *APIError) Error() string {
+	ids := []string{}
+	if e.CorrelationID != "" {
+		ids = append(ids, e.CorrelationID)
+	}
+	if e.ResponseCorrelationID != "" && e.ResponseCorrelationID != e.CorrelationID {
+		ids = append(ids, e.ResponseCorrelationID)
+	}
+
+	msg := e.Status
+	if e.Fault != nil {
+		msg += ": " + e.Fault.Error()
+	}
+
+	if len(ids) == 0 {
+		return msg
+	}
+
+	return msg + " (correlation id: " + strings.Join(ids, ", ") + ")"
+}
+
+// IsNotFound reports if err was caused by an entity that does not exist, i.e. the engine answered
+// with 404 or a name could not be resolved (ErrNotFound)
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrNotFound) || isStatus(err, http.StatusNotFound)
+}
+
+// isStatus reports if err is an APIError with the status code
+func isStatus(err error, code int) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == code
+}
@@ -0,0 +1,114 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+const conflictFault = `<fault>
+  <reason>Operation Failed</reason>
+  <detail>[Cannot add VM. The VM name is already in use.]</detail>
+</fault>`
+
+func TestFault(t *testing.T) {
+	e := newTestEngine(t)
+	e.handle("/vms", func(w http.ResponseWriter, r *http.Request) {
+		writeXML(w, http.StatusConflict, conflictFault)
+	})
+	e.handle("/hosts", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusConflict)
+		w.Write([]byte("<html>conflict</html>"))
+	})
+	c := e.client(t)
+
+	_, err := c.Post("/vms", nil)
+
+	var f *Fault
+	if !errors.As(err, &f) {
+		t.Fatalf("expected a Fault, got %v", err)
+	}
+
+	if f.Reason != "Operation Failed" || f.Detail != "[Cannot add VM. The VM name is already in use.]" {
+		t.Fatalf("unexpected fault %+v", f)
+	}
+
+	if want := "409 Conflict: Operation Failed: [Cannot add VM. The VM name is already in use.]"; err.Error() != want {
+		t.Fatalf("expected error %q, got %q", want, err)
+	}
+
+	_, err = c.Get("/hosts")
+	if errors.As(err, &f) {
+		t.Fatalf("unexpected fault for a body without fault: %+v", f)
+	}
+
+	if err == nil || err.Error() != "409 Conflict" {
+		t.Fatalf("expected the status as error, got %v", err)
+	}
+}
+
+func TestCorrelationIDInError(t *testing.T) {
+	e := newTestEngine(t)
+	e.handle("/vms", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Correlation-Id", "engine-id")
+		writeXML(w, http.StatusConflict, conflictFault)
+	})
+	c := e.client(t)
+
+	_, err := c.Post("/vms", nil, CorrelationID("client-id"))
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.CorrelationID != "client-id" || apiErr.ResponseCorrelationID != "engine-id" {
+		t.Fatalf("expected an APIError with both correlation ids, got %v", err)
+	}
+
+	if !strings.HasSuffix(err.Error(), "(correlation id: client-id, engine-id)") {
+		t.Fatalf("correlation ids missing in %q", err)
+	}
+}
+
+func TestAPIErrorStatus(t *testing.T) {
+	e := newTestEngine(t)
+	e.handle("/vms/missing", func(w http.ResponseWriter, r *http.Request) {
+		writeXML(w, http.StatusNotFound, `<fault><reason>Operation Failed</reason><detail>Entity not found: missing</detail></fault>`)
+	})
+	e.handle("/vms", func(w http.ResponseWriter, r *http.Request) {
+		writeXML(w, http.StatusConflict, conflictFault)
+	})
+	c := e.client(t)
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		status   int
+		notFound bool
+	}{
+		{"not found", "GET", "/vms/missing", http.StatusNotFound, true},
+		{"conflict", "POST", "/vms", http.StatusConflict, false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := c.SendRequest(tc.path, tc.method, nil)
+
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("expected an APIError, got %v", err)
+			}
+			if apiErr.StatusCode != tc.status || !strings.HasSuffix(apiErr.Status, http.StatusText(tc.status)) {
+				t.Fatalf("expected status %d, got %d (%s)", tc.status, apiErr.StatusCode, apiErr.Status)
+			}
+			if apiErr.Fault == nil {
+				t.Fatal("expected the fault to be parsed")
+			}
+			if got := IsNotFound(err); got != tc.notFound {
+				t.Fatalf("expected IsNotFound to return %t, got %t", tc.notFound, got)
+			}
+		})
+	}
+
+	if IsNotFound(nil) || IsNotFound(errors.New("404")) {
+		t.Fatal("IsNotFound has to be false for errors other than APIError")
+	}
+}
@@ -0,0 +1,24 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// ErrPreconditionFailed is returned by UpdateIfMatch if the entity was changed since the ETag was
+// retrieved. It wraps the APIError.
+var ErrPreconditionFailed = errors.New("entity was modified concurrently")
+
+// UpdateIfMatch updates the entity at path with obj (see SendObject) only if its current ETag
+// still is etag (as returned in Response.ETag by SendRaw), so concurrent changes are not overwritten.
+// The response is unmarshaled into res if it is not nil.
+func (c *Client) UpdateIfMatch(path string, obj interface{}, etag string, res interface{}) error {
+	err := c.SendObjectContext(context.Background(), path, "PUT", obj, res, Header("If-Match", etag))
+	if isStatus(err, http.StatusPreconditionFailed) {
+		return fmt.Errorf("%w: %w", ErrPreconditionFailed, err)
+	}
+
+	return err
+}
@@ -0,0 +1,55 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+)
+
+func TestUpdateIfMatch(t *testing.T) {
+	e := newTestEngine(t)
+	var mu sync.Mutex
+	etag := `"1"`
+	e.handle("/vms/123", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if r.Method == "PUT" {
+			if r.Header.Get("If-Match") != etag {
+				writeXML(w, http.StatusPreconditionFailed, `<fault><reason>Precondition Failed</reason></fault>`)
+				return
+			}
+			etag = `"2"`
+		}
+
+		w.Header().Set("ETag", etag)
+		writeXML(w, http.StatusOK, `<vm id="123"><name>web01</name></vm>`)
+	})
+	c := e.client(t)
+
+	resp, err := c.SendRaw("/vms/123", "GET", nil)
+	if err != nil {
+		t.Fatalf("SendRaw: %v", err)
+	}
+	if resp.ETag != `"1"` {
+		t.Fatalf("expected the ETag of the response, got %q", resp.ETag)
+	}
+
+	vm := &VM{}
+	err = c.UpdateIfMatch("/vms/123", &VM{Name: "web01"}, resp.ETag, vm)
+	if err != nil || vm.ID != "123" {
+		t.Fatalf("UpdateIfMatch: %+v, %v", vm, err)
+	}
+
+	// the entity was changed by the update, so the ETag is outdated
+	err = c.UpdateIfMatch("/vms/123", &VM{Name: "web02"}, resp.ETag, nil)
+	if !errors.Is(err, ErrPreconditionFailed) {
+		t.Fatalf("expected ErrPreconditionFailed, got %v", err)
+	}
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusPreconditionFailed {
+		t.Fatalf("expected the 412 to be wrapped, got %v", err)
+	}
+}
@@ -0,0 +1,148 @@
+package api
+
+import (
+	"context"
+	"encoding/xml"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Events is a collection of events as returned by the API
+type Events struct {
+	Events []Event `xml:"event"`
+}
+
+// Event is an entry of the audit log of the engine
+type Event struct {
+	XMLName       xml.Name  `xml:"event"`
+	ID            string    `xml:"id,attr,omitempty"`
+	Href          string    `xml:"href,attr,omitempty"`
+	Index         int       `xml:"index"`
+	Code          int       `xml:"code"`
+	Severity      string    `xml:"severity,omitempty"`
+	Time          time.Time `xml:"time"`
+	Description   string    `xml:"description,omitempty"`
+	Origin        string    `xml:"origin,omitempty"`
+	CorrelationID string    `xml:"correlation_id,omitempty"`
+	VM            *Link     `xml:"vm,omitempty"`
+	Host          *Link     `xml:"host,omitempty"`
+	Cluster       *Link     `xml:"cluster,omitempty"`
+	User          *Link     `xml:"user,omitempty"`
+}
+
+// Events retrieves up to max events (0 for the default limit of the engine) with an index greater
+// than from (0 for all), oldest first
+func (c *Client) Events(from, max int) ([]Event, error) {
+	return c.listEvents(context.Background(), "", from, max)
+}
+
+// TailEvents calls handler with every event created after it was called, oldest first, polling the
+// engine every interval (the default poll interval of 2 seconds if interval is not positive).
+// It returns ctx.Err() once ctx is done or the first error of a poll.
+func (c *Client) TailEvents(ctx context.Context, interval time.Duration, handler func(Event)) error {
+	if interval <= 0 {
+		interval = pollInterval
+	}
+
+	// without from the engine returns the newest events first
+	latest := &Events{}
+	err := c.GetAndParseContext(ctx, "/events?max=1", latest)
+	if err != nil {
+		return err
+	}
+
+	last := 0
+	if len(latest.Events) > 0 {
+		last = latest.Events[0].Index
+	}
+
+	for {
+		err := sleepContext(ctx, interval)
+		if err != nil {
+			return err
+		}
+
+		events, err := c.listEvents(ctx, "", last, 0)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+
+		for _, e := range events {
+			if e.Index <= last {
+				continue
+			}
+
+			handler(e)
+			last = e.Index
+		}
+	}
+}
+
+// listEvents retrieves up to max (0 for no limit) events matching query with an index greater
+// than from (0 for all). The events are returned in ascending order.
+func (c *Client) listEvents(ctx context.Context, query string, from, max int) ([]Event, error) {
+	path := "/events"
+	if query != "" {
+		path = searchPath(path, query)
+	}
+
+	addParam := func(name string, value int) {
+		sep := "?"
+		if strings.Contains(path, "?") {
+			sep = "&"
+		}
+		path += sep + name + "=" + strconv.Itoa(value)
+	}
+
+	if from > 0 {
+		addParam("from", from)
+	}
+
+	if max > 0 {
+		addParam("max", max)
+	}
+
+	res := &Events{}
+	err := c.GetAndParseContext(ctx, path, res)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(res.Events, func(i, j int) bool {
+		return res.Events[i].Index < res.Events[j].Index
+	})
+
+	return res.Events, nil
+}
+
+// GetVMEvents retrieves the events concerning a VM with an index greater than fromIndex
+// (0 for all), oldest first. Events referencing the VM only by name are included as well.
+func (c *Client) GetVMEvents(vmID string, fromIndex int) ([]Event, error) {
+	vm, err := c.GetVM(vmID)
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := c.listEvents(context.Background(), "vm.name="+quoteSearchValue(vm.Name), fromIndex, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]Event, 0, len(events))
+	for _, e := range events {
+		if e.VM == nil {
+			continue
+		}
+
+		if e.VM.ID == vmID || (e.VM.ID == "" && e.VM.Name == vm.Name) {
+			res = append(res, e)
+		}
+	}
+
+	return res, nil
+}
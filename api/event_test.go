@@ -0,0 +1,123 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// eventsXML returns the events with the indexes, newest first like the engine
+func eventsXML(indexes ...int) string {
+	b := &strings.Builder{}
+	b.WriteString("<events>")
+	for i := len(indexes) - 1; i >= 0; i-- {
+		fmt.Fprintf(b, `<event id="%d"><index>%d</index><code>%d</code><severity>normal</severity>`+
+			`<time>2021-03-04T10:15:30.123+01:00</time><description>event %d</description></event>`,
+			indexes[i], indexes[i], 30+indexes[i], indexes[i])
+	}
+	b.WriteString("</events>")
+
+	return b.String()
+}
+
+func TestEvents(t *testing.T) {
+	e := newTestEngine(t)
+	var query string
+	e.handle("/events", func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		writeXML(w, http.StatusOK, eventsXML(6, 7, 8))
+	})
+	c := e.client(t)
+
+	events, err := c.Events(5, 3)
+	if err != nil {
+		t.Fatalf("Events: %v", err)
+	}
+	if query != "from=5&max=3" {
+		t.Fatalf("unexpected query %q", query)
+	}
+
+	if len(events) != 3 || events[0].Index != 6 || events[2].Index != 8 {
+		t.Fatalf("expected the events oldest first, got %+v", events)
+	}
+	ev := events[0]
+	if ev.ID != "6" || ev.Code != 36 || ev.Severity != "normal" || ev.Description != "event 6" || ev.Time.IsZero() {
+		t.Fatalf("unexpected event %+v", ev)
+	}
+}
+
+func TestTailEvents(t *testing.T) {
+	e := newTestEngine(t)
+	polls := 0
+	e.handle("/events", func(w http.ResponseWriter, r *http.Request) {
+		from, _ := strconv.Atoi(r.URL.Query().Get("from"))
+		if from == 0 {
+			// the newest event when tailing starts
+			writeXML(w, http.StatusOK, eventsXML(10))
+			return
+		}
+
+		// the second batch is created after the first poll; the engine includes the event at from
+		polls++
+		newest := 12
+		if polls > 1 {
+			newest = 14
+		}
+
+		indexes := []int{}
+		for i := from; i <= newest; i++ {
+			indexes = append(indexes, i)
+		}
+		writeXML(w, http.StatusOK, eventsXML(indexes...))
+	})
+	c := e.client(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	seen := []int{}
+	err := c.TailEvents(ctx, time.Millisecond, func(ev Event) {
+		seen = append(seen, ev.Index)
+		if len(seen) == 4 {
+			cancel()
+		}
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+
+	if got := fmt.Sprint(seen); got != "[11 12 13 14]" {
+		t.Fatalf("expected every new event once, got %s", got)
+	}
+}
+
+func TestTailEventsDefaultInterval(t *testing.T) {
+	e := newTestEngine(t)
+	setPollInterval(t, 20*time.Millisecond)
+	polls := 0
+	e.handle("/events", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("from") != "" {
+			polls++
+		}
+		writeXML(w, http.StatusOK, eventsXML(10))
+	})
+	c := e.client(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 110*time.Millisecond)
+	defer cancel()
+
+	err := c.TailEvents(ctx, 0, func(ev Event) {})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+
+	// a zero interval must not poll the engine in a tight loop
+	if polls < 2 || polls > 6 {
+		t.Fatalf("expected to poll every 20ms, got %d polls in 110ms", polls)
+	}
+}
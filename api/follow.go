@@ -0,0 +1,69 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Follow retrieves the entity or collection an href of a response points to (e.g. the href of a
+// link element) and unmarshals it into v
+func (c *Client) Follow(href string, v interface{}, opts ...RequestOption) error {
+	return c.GetAndParse(c.relativePath(href), v, opts...)
+}
+
+// relativePath converts an href returned by the engine (e.g. /ovirt-engine/api/vms/123/nics)
+// into a path relative to the base URL of the client. The prefix of the href may differ from the
+// base URL if the API is accessed through a proxy (e.g. https://proxy/api), so everything up to
+// and including the /api segment is stripped as fallback.
+func (c *Client) relativePath(href string) string {
+	if u, err := url.Parse(href); err == nil && u.IsAbs() {
+		href = u.RequestURI()
+	}
+
+	base := ""
+	if u, err := url.Parse(c.url); err == nil {
+		base = strings.TrimRight(u.Path, "/")
+	}
+
+	if base != "" && strings.HasPrefix(href, base+"/") {
+		return strings.TrimPrefix(href, base)
+	}
+
+	if i := strings.Index(href, "/api/"); i >= 0 {
+		return href[i+len("/api"):]
+	}
+
+	return href
+}
+
+// GetFollow retrieves path with the links listed in follow expanded inline (e.g. "nics" or
+// "disk_attachments.disk"), saving a request per linked entity, and unmarshals it into v.
+// The follow parameter is supported from engine version 4.2 on; an error is returned for older engines.
+func (c *Client) GetFollow(path string, follow []string, v interface{}, opts ...RequestOption) error {
+	if len(follow) == 0 {
+		return c.GetAndParse(path, v, opts...)
+	}
+
+	version, err := c.Version()
+	if err != nil {
+		return err
+	}
+
+	if !version.AtLeast(4, 2) {
+		return fmt.Errorf("the follow parameter requires engine version 4.2 or newer, engine is %s", version.FullVersion)
+	}
+
+	sep := "?"
+	if strings.Contains(path, "?") {
+		sep = "&"
+	}
+
+	err = c.GetAndParse(path+sep+"follow="+url.QueryEscape(strings.Join(follow, ",")), v, opts...)
+	if isStatus(err, http.StatusBadRequest) {
+		return fmt.Errorf("engine rejected follow=%s: %w", strings.Join(follow, ","), err)
+	}
+
+	return err
+}
@@ -0,0 +1,111 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestFollow(t *testing.T) {
+	e := newTestEngine(t)
+	e.handle("/vms/123/nics", func(w http.ResponseWriter, r *http.Request) {
+		writeXML(w, http.StatusOK, `<nics><nic id="1"><name>nic1</name></nic></nics>`)
+	})
+	c := e.client(t)
+
+	hrefs := []string{
+		"/ovirt-engine/api/vms/123/nics",
+		"/api/vms/123/nics",
+		e.apiURL() + "/vms/123/nics",
+	}
+
+	for _, href := range hrefs {
+		nics := &NICs{}
+		err := c.Follow(href, nics)
+		if err != nil {
+			t.Fatalf("%s: %v", href, err)
+		}
+
+		if len(nics.NICs) != 1 || nics.NICs[0].Name != "nic1" {
+			t.Fatalf("%s: unexpected nics %+v", href, nics)
+		}
+	}
+}
+
+func TestRelativePath(t *testing.T) {
+	tests := []struct {
+		base, href, want string
+	}{
+		{"https://engine/ovirt-engine/api", "/ovirt-engine/api/vms/123/nics", "/vms/123/nics"},
+		{"https://engine/ovirt-engine/api", "/api/vms/123/nics", "/vms/123/nics"},
+		{"https://proxy/api", "/ovirt-engine/api/vms/123/nics", "/vms/123/nics"},
+		{"https://proxy/api", "/api/vms/123", "/vms/123"},
+		{"https://engine/ovirt-engine/api", "https://engine/ovirt-engine/api/vms/123?follow=nics", "/vms/123?follow=nics"},
+		{"https://engine/ovirt-engine/api", "/vms/123", "/vms/123"},
+	}
+
+	for _, tc := range tests {
+		c := &Client{url: tc.base}
+		if got := c.relativePath(tc.href); got != tc.want {
+			t.Errorf("%s with base %s: expected %s, got %s", tc.href, tc.base, tc.want, got)
+		}
+	}
+}
+
+func TestGetFollow(t *testing.T) {
+	e := newTestEngine(t)
+	e.handleAPIDocument()
+	var query string
+	e.handle("/vms/123", func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		writeXML(w, http.StatusOK, `<vm id="123"><name>web01</name>
+  <nics><nic id="n1"><name>nic1</name><network id="net1"/></nic></nics>
+  <disk_attachments><disk_attachment id="d1"><bootable>true</bootable><disk id="d1"><name>root</name></disk></disk_attachment></disk_attachments>
+</vm>`)
+	})
+	e.handle("/vms/456", func(w http.ResponseWriter, r *http.Request) {
+		writeXML(w, http.StatusBadRequest, `<fault><reason>Bad Request</reason></fault>`)
+	})
+	c := e.client(t)
+
+	err := c.GetFollow("/vms/456", []string{"nics"}, &VM{})
+	if err == nil || !strings.HasPrefix(err.Error(), "engine rejected follow=nics") || !isStatus(err, http.StatusBadRequest) {
+		t.Fatalf("expected the rejected follow parameter to be reported, got %v", err)
+	}
+
+	vm := &VM{}
+	err = c.GetFollow("/vms/123", []string{"disk_attachments.disk", "nics"}, vm)
+	if err != nil {
+		t.Fatalf("GetFollow: %v", err)
+	}
+	if query != "follow=disk_attachments.disk%2Cnics" {
+		t.Fatalf("unexpected query %q", query)
+	}
+
+	if vm.NICs == nil || len(vm.NICs.NICs) != 1 || vm.NICs.NICs[0].Network == nil || vm.NICs.NICs[0].Network.ID != "net1" {
+		t.Fatalf("expected the nics to be expanded, got %+v", vm.NICs)
+	}
+	das := vm.DiskAttachments
+	if das == nil || len(das.DiskAttachments) != 1 || !das.DiskAttachments[0].Bootable || das.DiskAttachments[0].Disk.ID != "d1" {
+		t.Fatalf("expected the disk attachments to be expanded, got %+v", das)
+	}
+}
+
+func TestGetFollowOldEngine(t *testing.T) {
+	e := newTestEngine(t)
+	e.handle("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != testAPIPath+"/" {
+			t.Errorf("unexpected request %s", r.URL)
+			http.NotFound(w, r)
+			return
+		}
+		doc := strings.Replace(apiDocument, "<minor>4</minor>", "<minor>1</minor>", 1)
+		writeXML(w, http.StatusOK, strings.Replace(doc, "4.4.10.6-1.el8", "4.1.9-1.el7", 1))
+	})
+	c := e.client(t)
+
+	err := c.GetFollow("/vms/123", []string{"nics"}, &VM{})
+	if err == nil || !strings.Contains(err.Error(), "requires engine version 4.2") || !strings.Contains(err.Error(), "4.1.9-1.el7") {
+		t.Fatalf("expected the engine version to be rejected, got %v", err)
+	}
+}
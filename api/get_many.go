@@ -0,0 +1,69 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// GetMany retrieves the paths concurrently (at most maxConcurrency at a time) and returns the
+// response bodies by path. The first failing request cancels the outstanding ones; its error is
+// returned along with the bodies retrieved so far. Requests are subject to WithRateLimit.
+func (c *Client) GetMany(paths []string, maxConcurrency int, opts ...RequestOption) (map[string][]byte, error) {
+	return c.GetManyContext(context.Background(), paths, maxConcurrency, opts...)
+}
+
+// GetManyContext retrieves the paths concurrently and returns the response bodies by path
+func (c *Client) GetManyContext(ctx context.Context, paths []string, maxConcurrency int, opts ...RequestOption) (map[string][]byte, error) {
+	if maxConcurrency <= 0 {
+		maxConcurrency = 1
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(map[string][]byte, len(paths))
+	var firstErr error
+	mu := sync.Mutex{}
+
+	sem := make(chan struct{}, maxConcurrency)
+	wg := sync.WaitGroup{}
+
+	for _, path := range paths {
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		sem <- struct{}{}
+
+		go func(path string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			b, err := c.GetContext(ctx, path, opts...)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			if err != nil {
+				if firstErr == nil {
+					firstErr = fmt.Errorf("%s: %w", path, err)
+					cancel()
+				}
+				return
+			}
+
+			results[path] = b
+		}(path)
+	}
+	wg.Wait()
+
+	if firstErr == nil && ctx.Err() != nil {
+		firstErr = ctx.Err()
+	}
+
+	return results, firstErr
+}
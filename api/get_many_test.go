@@ -0,0 +1,75 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGetMany(t *testing.T) {
+	e := newTestEngine(t)
+	var inFlight, maxInFlight int32
+	e.handle("/vms/", func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			max := atomic.LoadInt32(&maxInFlight)
+			if n <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, n) {
+				break
+			}
+		}
+
+		time.Sleep(5 * time.Millisecond)
+		id := strings.TrimPrefix(r.URL.Path, testAPIPath+"/vms/")
+		writeXML(w, http.StatusOK, fmt.Sprintf(`<vm id="%s"/>`, id))
+	})
+	c := e.client(t)
+
+	paths := make([]string, 20)
+	for i := range paths {
+		paths[i] = fmt.Sprintf("/vms/%d", i)
+	}
+
+	results, err := c.GetMany(paths, 4)
+	if err != nil {
+		t.Fatalf("GetMany: %v", err)
+	}
+
+	if len(results) != len(paths) {
+		t.Fatalf("expected %d results, got %d", len(paths), len(results))
+	}
+	for i, path := range paths {
+		if want := fmt.Sprintf(`<vm id="%d"/>`, i); string(results[path]) != want {
+			t.Errorf("%s: expected %s, got %s", path, want, results[path])
+		}
+	}
+
+	if max := atomic.LoadInt32(&maxInFlight); max > 4 || max < 2 {
+		t.Fatalf("expected up to 4 requests in flight, got %d", max)
+	}
+}
+
+func TestGetManyError(t *testing.T) {
+	e := newTestEngine(t)
+	e.handle("/vms/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == testAPIPath+"/vms/3" {
+			writeXML(w, http.StatusNotFound, "")
+			return
+		}
+		writeXML(w, http.StatusOK, "<vm/>")
+	})
+	c := e.client(t)
+
+	paths := make([]string, 20)
+	for i := range paths {
+		paths[i] = fmt.Sprintf("/vms/%d", i)
+	}
+
+	_, err := c.GetMany(paths, 4)
+	if !IsNotFound(err) || !strings.Contains(err.Error(), "/vms/3") {
+		t.Fatalf("expected the 404 of /vms/3, got %v", err)
+	}
+}
@@ -0,0 +1,72 @@
+package api
+
+import "encoding/xml"
+
+// GlusterVolumes is a collection of gluster volumes as returned by the API
+type GlusterVolumes struct {
+	GlusterVolumes []GlusterVolume `xml:"gluster_volume"`
+}
+
+// GlusterVolume is a gluster volume of a hyperconverged cluster
+type GlusterVolume struct {
+	XMLName      xml.Name       `xml:"gluster_volume"`
+	ID           string         `xml:"id,attr,omitempty"`
+	Href         string         `xml:"href,attr,omitempty"`
+	Name         string         `xml:"name,omitempty"`
+	VolumeType   string         `xml:"volume_type,omitempty"`
+	Status       string         `xml:"status,omitempty"`
+	ReplicaCount int            `xml:"replica_count,omitempty"`
+	Bricks       []GlusterBrick `xml:"bricks>brick,omitempty"`
+	Cluster      *Link          `xml:"cluster,omitempty"`
+}
+
+// GlusterBricks is a collection of gluster bricks as returned by the API
+type GlusterBricks struct {
+	GlusterBricks []GlusterBrick `xml:"brick"`
+}
+
+// GlusterBrick is a directory on a host providing storage to a gluster volume
+type GlusterBrick struct {
+	ID       string `xml:"id,attr,omitempty"`
+	Href     string `xml:"href,attr,omitempty"`
+	Name     string `xml:"name,omitempty"`
+	ServerID string `xml:"server_id,omitempty"`
+	BrickDir string `xml:"brick_dir,omitempty"`
+	Status   string `xml:"status,omitempty"`
+}
+
+func glusterVolumePath(clusterID, volumeID string) string {
+	return "/clusters/" + clusterID + "/glustervolumes/" + volumeID
+}
+
+// ListGlusterVolumes retrieves the gluster volumes of a cluster
+func (c *Client) ListGlusterVolumes(clusterID string) ([]GlusterVolume, error) {
+	res := &GlusterVolumes{}
+	err := c.GetAndParse("/clusters/"+clusterID+"/glustervolumes", res)
+	if err != nil {
+		return nil, err
+	}
+
+	return res.GlusterVolumes, nil
+}
+
+// ListGlusterBricks retrieves the bricks of a gluster volume
+func (c *Client) ListGlusterBricks(clusterID, volumeID string) ([]GlusterBrick, error) {
+	res := &GlusterBricks{}
+	err := c.GetAndParse(glusterVolumePath(clusterID, volumeID)+"/glusterbricks", res)
+	if err != nil {
+		return nil, err
+	}
+
+	return res.GlusterBricks, nil
+}
+
+// StartGlusterVolume starts a gluster volume. force also starts bricks which are down.
+func (c *Client) StartGlusterVolume(clusterID, volumeID string, force bool) (*Action, error) {
+	return c.performAction(glusterVolumePath(clusterID, volumeID), "start", &Action{Force: force})
+}
+
+// StopGlusterVolume stops a gluster volume. force stops it even if it is in use.
+func (c *Client) StopGlusterVolume(clusterID, volumeID string, force bool) (*Action, error) {
+	return c.performAction(glusterVolumePath(clusterID, volumeID), "stop", &Action{Force: force})
+}
@@ -0,0 +1,236 @@
+package api
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testAPIPath is the path the test engine serves the API at
+const testAPIPath = "/ovirt-engine/api"
+
+// testEngine is a fake engine serving the SSO endpoints and the API
+type testEngine struct {
+	*httptest.Server
+	mux *http.ServeMux
+
+	// tokenRequests counts the requests to the token endpoint, which issues token-1, token-2, ...
+	tokenRequests int32
+
+	mu        sync.Mutex
+	tokenForm url.Values
+}
+
+func newTestEngine(t *testing.T) *testEngine {
+	return startTestEngine(t, nil)
+}
+
+// newTLSTestEngine returns an engine serving HTTPS with the TLS config
+func newTLSTestEngine(t *testing.T, cfg *tls.Config) *testEngine {
+	return startTestEngine(t, cfg)
+}
+
+func startTestEngine(t *testing.T, cfg *tls.Config) *testEngine {
+	t.Helper()
+
+	e := &testEngine{mux: http.NewServeMux()}
+	e.Server = httptest.NewUnstartedServer(e.mux)
+	e.Config.ErrorLog = log.New(io.Discard, "", 0)
+	if cfg != nil {
+		e.TLS = cfg
+		e.StartTLS()
+	} else {
+		e.Start()
+	}
+	t.Cleanup(e.Close)
+
+	e.mux.HandleFunc("/ovirt-engine/sso/oauth/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		e.mu.Lock()
+		e.tokenForm = r.PostForm
+		e.mu.Unlock()
+
+		n := atomic.AddInt32(&e.tokenRequests, 1)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"access_token":"token-%d"}`, n)
+	})
+
+	return e
+}
+
+// apiURL returns the base URL of the API
+func (e *testEngine) apiURL() string {
+	return e.URL + testAPIPath
+}
+
+// tokens returns the number of tokens issued so far
+func (e *testEngine) tokens() int {
+	return int(atomic.LoadInt32(&e.tokenRequests))
+}
+
+// lastTokenForm returns the form of the last token request
+func (e *testEngine) lastTokenForm() url.Values {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.tokenForm
+}
+
+// handle registers h for the pattern relative to the API (e.g. "/vms")
+func (e *testEngine) handle(pattern string, h http.HandlerFunc) {
+	e.mux.HandleFunc(testAPIPath+pattern, h)
+}
+
+// client returns a client authenticated with the token endpoint of the engine
+func (e *testEngine) client(t *testing.T, opts ...ClientOption) *Client {
+	t.Helper()
+
+	c, err := NewClient(e.apiURL(), "user", "secret", opts...)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	return c
+}
+
+// writeXML answers a request with status and the XML document body
+func writeXML(w http.ResponseWriter, status int, body string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	fmt.Fprint(w, body)
+}
+
+// testLogger records all messages logged by a client
+type testLogger struct {
+	mu       sync.Mutex
+	messages []string
+}
+
+func (l *testLogger) Infof(format string, args ...interface{}) {
+	l.log(format, args...)
+}
+
+func (l *testLogger) Debugf(format string, args ...interface{}) {
+	l.log(format, args...)
+}
+
+func (l *testLogger) Errorf(format string, args ...interface{}) {
+	l.log(format, args...)
+}
+
+func (l *testLogger) log(format string, args ...interface{}) {
+	l.mu.Lock()
+	l.messages = append(l.messages, fmt.Sprintf(format, args...))
+	l.mu.Unlock()
+}
+
+// output returns all messages logged so far, one per line
+func (l *testLogger) output() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return strings.Join(l.messages, "\n")
+}
+
+// recordingTransport passes requests to http.DefaultTransport and records their paths
+type recordingTransport struct {
+	mu    sync.Mutex
+	paths []string
+}
+
+func (rt *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	rt.mu.Lock()
+	rt.paths = append(rt.paths, req.URL.Path)
+	rt.mu.Unlock()
+
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func (rt *recordingTransport) requests() []string {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	return append([]string(nil), rt.paths...)
+}
+
+// headerRecorder answers requests with body and records their headers
+type headerRecorder struct {
+	body string
+
+	mu      sync.Mutex
+	headers []http.Header
+}
+
+func (hr *headerRecorder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	hr.mu.Lock()
+	hr.headers = append(hr.headers, r.Header.Clone())
+	hr.mu.Unlock()
+
+	writeXML(w, http.StatusOK, hr.body)
+}
+
+// last returns the headers of the last request
+func (hr *headerRecorder) last() http.Header {
+	hr.mu.Lock()
+	defer hr.mu.Unlock()
+
+	if len(hr.headers) == 0 {
+		return nil
+	}
+
+	return hr.headers[len(hr.headers)-1]
+}
+
+// setPollInterval shortens the delay between polls for the duration of the test
+func setPollInterval(t *testing.T, d time.Duration) {
+	old := pollInterval
+	pollInterval = d
+	t.Cleanup(func() {
+		pollInterval = old
+	})
+}
+
+// fakeClock is a clock for WithClock which only moves when advanced
+type fakeClock struct {
+	mu sync.Mutex
+	t  time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{t: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.t
+}
+
+func (c *fakeClock) advance(d time.Duration) {
+	c.mu.Lock()
+	c.t = c.t.Add(d)
+	c.mu.Unlock()
+}
+
+// handleExpiringTokens serves a token endpoint at path issuing tokens valid for lifetime
+// and returns the number of tokens issued
+func (e *testEngine) handleExpiringTokens(path string, lifetime time.Duration) *int32 {
+	var n int32
+	e.mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+		i := atomic.AddInt32(&n, 1)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"access_token":"expiring-%d","expires_in":%d}`, i, int(lifetime.Seconds()))
+	})
+
+	return &n
+}
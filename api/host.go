@@ -0,0 +1,68 @@
+package api
+
+import "encoding/xml"
+
+// Hosts is a collection of hosts as returned by the API
+type Hosts struct {
+	Hosts []Host `xml:"host"`
+}
+
+// Host represents a hypervisor managed by the engine
+type Host struct {
+	XMLName           xml.Name      `xml:"host"`
+	ID                string        `xml:"id,attr,omitempty"`
+	Href              string        `xml:"href,attr,omitempty"`
+	Name              string        `xml:"name,omitempty"`
+	Description       string        `xml:"description,omitempty"`
+	Address           string        `xml:"address,omitempty"`
+	Status            string        `xml:"status,omitempty"`
+	Memory            int64         `xml:"memory,omitempty"`
+	CPU               *HostCPU      `xml:"cpu,omitempty"`
+	Cluster           *Link         `xml:"cluster,omitempty"`
+	HostedEngine      *HostedEngine `xml:"hosted_engine,omitempty"`
+	SupportedVersions []Version     `xml:"supported_versions>version,omitempty"`
+}
+
+// HostCPU describes the physical CPU of a host
+type HostCPU struct {
+	Name     string       `xml:"name,omitempty"`
+	Speed    int          `xml:"speed,omitempty"`
+	Topology *CPUTopology `xml:"topology,omitempty"`
+}
+
+// HostedEngine is the hosted engine HA state reported by a host
+type HostedEngine struct {
+	Configured        bool `xml:"configured"`
+	Active            bool `xml:"active"`
+	GlobalMaintenance bool `xml:"global_maintenance"`
+	LocalMaintenance  bool `xml:"local_maintenance"`
+	Score             int  `xml:"score"`
+}
+
+// GetHost retrieves a host by id
+func (c *Client) GetHost(id string) (*Host, error) {
+	res := &Host{}
+	err := c.GetAndParse("/hosts/"+id, res)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// ListVMsOnHost retrieves the VMs currently running on a host.
+// The search query of the engine matches hosts by name, so the host is looked up first.
+func (c *Client) ListVMsOnHost(hostID string) ([]VM, error) {
+	h, err := c.GetHost(hostID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &VMs{}
+	err = c.GetAndParse(searchPath("/vms", "host="+quoteSearchValue(h.Name)), res)
+	if err != nil {
+		return nil, err
+	}
+
+	return res.VMs, nil
+}
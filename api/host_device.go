@@ -0,0 +1,53 @@
+package api
+
+import (
+	"encoding/xml"
+	"errors"
+)
+
+// HostDevices is a collection of host devices as returned by the API
+type HostDevices struct {
+	HostDevices []HostDevice `xml:"host_device"`
+}
+
+// HostDevice is a device of a host (e.g. a PCI card) which can be passed through to a VM
+type HostDevice struct {
+	XMLName     xml.Name      `xml:"host_device"`
+	ID          string        `xml:"id,attr,omitempty"`
+	Href        string        `xml:"href,attr,omitempty"`
+	Name        string        `xml:"name,omitempty"`
+	Capability  string        `xml:"capability,omitempty"`
+	Driver      string        `xml:"driver,omitempty"`
+	IommuGroup  int           `xml:"iommu_group,omitempty"`
+	Vendor      *DeviceVendor `xml:"vendor,omitempty"`
+	Product     *DeviceVendor `xml:"product,omitempty"`
+	Placeholder bool          `xml:"placeholder,omitempty"`
+	Host        *Link         `xml:"host,omitempty"`
+	VM          *Link         `xml:"vm,omitempty"`
+}
+
+// DeviceVendor identifies the vendor or product of a host device
+type DeviceVendor struct {
+	ID   string `xml:"id,attr,omitempty"`
+	Name string `xml:"name,omitempty"`
+}
+
+// ListHostDevices retrieves the devices of a host
+func (c *Client) ListHostDevices(hostID string) ([]HostDevice, error) {
+	res := &HostDevices{}
+	err := c.GetAndParse("/hosts/"+hostID+"/devices", res)
+	if err != nil {
+		return nil, err
+	}
+
+	return res.HostDevices, nil
+}
+
+// AttachHostDevice passes the host device through to a VM
+func (c *Client) AttachHostDevice(vmID, deviceID string) error {
+	if deviceID == "" {
+		return errors.New("device id must not be empty")
+	}
+
+	return c.SendObject("/vms/"+vmID+"/hostdevices", "POST", &HostDevice{ID: deviceID}, &HostDevice{})
+}
@@ -0,0 +1,273 @@
+package api
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+)
+
+// ImageTransferPhase is the phase of an image transfer
+type ImageTransferPhase string
+
+const (
+	// ImageTransferInitializing means the engine is preparing the transfer
+	ImageTransferInitializing ImageTransferPhase = "initializing"
+	// ImageTransferTransferring means data can be sent to or read from the transfer URL
+	ImageTransferTransferring ImageTransferPhase = "transferring"
+	// ImageTransferFinalizingSuccess means the engine is verifying the transferred image
+	ImageTransferFinalizingSuccess ImageTransferPhase = "finalizing_success"
+	// ImageTransferFinishedSuccess means the transfer completed
+	ImageTransferFinishedSuccess ImageTransferPhase = "finished_success"
+	// ImageTransferFinishedFailure means the transfer failed or was cancelled
+	ImageTransferFinishedFailure ImageTransferPhase = "finished_failure"
+)
+
+// ImageTransfer is an upload or download of a disk image through the image I/O daemon
+type ImageTransfer struct {
+	XMLName     xml.Name           `xml:"image_transfer"`
+	ID          string             `xml:"id,attr,omitempty"`
+	Href        string             `xml:"href,attr,omitempty"`
+	Direction   string             `xml:"direction,omitempty"`
+	Phase       ImageTransferPhase `xml:"phase,omitempty"`
+	TransferURL string             `xml:"transfer_url,omitempty"`
+	ProxyURL    string             `xml:"proxy_url,omitempty"`
+	Disk        *Link              `xml:"disk,omitempty"`
+}
+
+// imageTransferTimeout is how long to wait for a transfer to become ready or to finish (unless
+// the context passed to UploadImageContext or DownloadImageContext is done earlier)
+var imageTransferTimeout = 5 * time.Minute
+
+// imageChunkSize is the size of the ranges an image is uploaded in
+const imageChunkSize = 8 << 20
+
+// UploadImage uploads size bytes read from r into the disk, which has to have at least this size.
+// The data is sent to the transfer URL of the host (or the proxy URL if the host is not reachable)
+// using the HTTP client of the client, so TLS options apply. The transfer is cancelled if the upload fails.
+func (c *Client) UploadImage(diskID string, r io.Reader, size int64) error {
+	return c.UploadImageContext(context.Background(), diskID, r, size)
+}
+
+// UploadImageContext uploads size bytes read from r into the disk. The transfer is cancelled if
+// the upload fails or ctx is done before it completed.
+func (c *Client) UploadImageContext(ctx context.Context, diskID string, r io.Reader, size int64) error {
+	t, err := c.startImageTransfer(ctx, diskID, "upload")
+	if err != nil {
+		return err
+	}
+
+	cr := &countingReader{r: r}
+	offset := int64(0)
+	err = c.transferWithFallback(t, func(url string) error {
+		// the proxy continues at the first chunk the host did not receive
+		var err error
+		offset, err = c.uploadChunks(ctx, url, cr, offset, size)
+		return err
+	})
+	if err != nil {
+		return c.cancelImageTransfer(t, err)
+	}
+
+	return c.finalizeImageTransfer(ctx, t)
+}
+
+// DownloadImage downloads the image of the disk and writes it to w
+func (c *Client) DownloadImage(diskID string, w io.Writer) error {
+	return c.DownloadImageContext(context.Background(), diskID, w)
+}
+
+// DownloadImageContext downloads the image of the disk and writes it to w. The transfer is
+// cancelled if the download fails or ctx is done before it completed.
+func (c *Client) DownloadImageContext(ctx context.Context, diskID string, w io.Writer) error {
+	t, err := c.startImageTransfer(ctx, diskID, "download")
+	if err != nil {
+		return err
+	}
+
+	err = c.transferWithFallback(t, func(url string) error {
+		return c.download(ctx, url, w)
+	})
+	if err != nil {
+		return c.cancelImageTransfer(t, err)
+	}
+
+	return c.finalizeImageTransfer(ctx, t)
+}
+
+// startImageTransfer creates the transfer and waits until data can be transferred
+func (c *Client) startImageTransfer(ctx context.Context, diskID, direction string) (*ImageTransfer, error) {
+	t := &ImageTransfer{}
+	err := c.SendObjectContext(ctx, "/imagetransfers", "POST", &ImageTransfer{Direction: direction, Disk: &Link{ID: diskID}}, t)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, imageTransferTimeout)
+	defer cancel()
+
+	_, err = waitForStatus(ctx, "image transfer "+t.ID, string(ImageTransferTransferring), func(ctx context.Context) (string, error) {
+		err := c.GetAndParseContext(ctx, "/imagetransfers/"+t.ID, t)
+		if err == nil && t.Phase == ImageTransferFinishedFailure {
+			err = fmt.Errorf("image transfer %s failed", t.ID)
+		}
+
+		return string(t.Phase), err
+	})
+	if err != nil {
+		return nil, c.cancelImageTransfer(t, err)
+	}
+
+	return t, nil
+}
+
+// transferWithFallback transfers the data using the transfer URL of the host and falls back to
+// the proxy URL, but only if transfer returned a transferConnectError (so no data was sent)
+func (c *Client) transferWithFallback(t *ImageTransfer, transfer func(url string) error) error {
+	if t.TransferURL == "" {
+		if t.ProxyURL == "" {
+			return fmt.Errorf("image transfer %s has no transfer url", t.ID)
+		}
+
+		return transfer(t.ProxyURL)
+	}
+
+	err := transfer(t.TransferURL)
+	var connErr *transferConnectError
+	if errors.As(err, &connErr) && t.ProxyURL != "" {
+		c.logger.Debugf("could not connect to %s, using proxy: %v", t.TransferURL, err)
+		return transfer(t.ProxyURL)
+	}
+
+	return err
+}
+
+// transferConnectError is returned if a request to the transfer URL failed because the host
+// could not be connected, so none of the data to transfer was sent
+type transferConnectError struct {
+	err error
+}
+
+func (e *transferConnectError) Error() string {
+	return e.err.Error()
+}
+
+func (e *transferConnectError) Unwrap() error {
+	return e.err
+}
+
+// uploadChunks uploads the data of r from offset on and returns the offset of the first chunk
+// which was not uploaded
+func (c *Client) uploadChunks(ctx context.Context, url string, r *countingReader, offset, size int64) (int64, error) {
+	for ; offset < size; offset += imageChunkSize {
+		n := size - offset
+		if n > imageChunkSize {
+			n = imageChunkSize
+		}
+
+		body := newRateLimitedReader(ctx, io.LimitReader(r, n), c.transferRateLimit)
+		req, err := http.NewRequestWithContext(ctx, "PUT", url, body)
+		if err != nil {
+			return offset, err
+		}
+		req.ContentLength = n
+		req.Header.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", offset, offset+n-1, size))
+		if c.expectContinue > 0 {
+			req.Header.Set("Expect", "100-continue")
+		}
+
+		resp, err := c.client.Do(req)
+		if err != nil {
+			if isDialError(err) {
+				return offset, &transferConnectError{err: err}
+			}
+			return offset, err
+		}
+
+		err = checkTransferResponse(resp)
+		if err != nil {
+			return offset, err
+		}
+	}
+
+	return offset, nil
+}
+
+// isDialError reports if err occurred while connecting, before anything was sent
+func isDialError(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+func (c *Client) download(ctx context.Context, url string, w io.Writer) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return &transferConnectError{err: err}
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return checkTransferResponse(resp)
+	}
+	defer resp.Body.Close()
+
+	_, err = io.Copy(w, newRateLimitedReader(ctx, resp.Body, c.transferRateLimit))
+	return err
+}
+
+// checkTransferResponse closes the response of the image I/O daemon and returns an error
+// containing the message of the daemon if the request failed
+func checkTransferResponse(resp *http.Response) error {
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 300 {
+		_, err := io.Copy(io.Discard, resp.Body)
+		return err
+	}
+
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	return fmt.Errorf("image transfer failed: %s: %s", resp.Status, msg)
+}
+
+// finalizeImageTransfer finalizes the transfer and waits until the engine verified the image
+func (c *Client) finalizeImageTransfer(ctx context.Context, t *ImageTransfer) error {
+	_, err := c.performAction("/imagetransfers/"+t.ID, "finalize", nil)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, imageTransferTimeout)
+	defer cancel()
+
+	_, err = waitForStatus(ctx, "image transfer "+t.ID, string(ImageTransferFinishedSuccess), func(ctx context.Context) (string, error) {
+		err := c.GetAndParseContext(ctx, "/imagetransfers/"+t.ID, t)
+		if IsNotFound(err) {
+			// newer engines remove the transfer once it is finished
+			return string(ImageTransferFinishedSuccess), nil
+		}
+		if err == nil && t.Phase == ImageTransferFinishedFailure {
+			err = fmt.Errorf("image transfer %s failed", t.ID)
+		}
+
+		return string(t.Phase), err
+	})
+	return err
+}
+
+// cancelImageTransfer cancels the transfer after it failed with err and returns err
+func (c *Client) cancelImageTransfer(t *ImageTransfer, err error) error {
+	_, cerr := c.performAction("/imagetransfers/"+t.ID, "cancel", nil)
+	if cerr != nil {
+		c.logger.Debugf("could not cancel image transfer %s: %v", t.ID, cerr)
+	}
+
+	return err
+}
@@ -0,0 +1,303 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeImageTransfer serves the image transfer endpoints of the engine and an image I/O daemon
+type fakeImageTransfer struct {
+	daemon *httptest.Server
+
+	// transferURL and proxyURL are reported by the engine once the transfer is ready
+	transferURL string
+	proxyURL    string
+
+	mu        sync.Mutex
+	created   *ImageTransfer
+	polls     int
+	finalized bool
+	cancelled bool
+	image     []byte
+	ranges    []string
+}
+
+func newFakeImageTransfer(t *testing.T, e *testEngine, image []byte) *fakeImageTransfer {
+	f := &fakeImageTransfer{image: image}
+	f.daemon = httptest.NewServer(http.HandlerFunc(f.serveDaemon))
+	t.Cleanup(f.daemon.Close)
+	f.transferURL = f.daemon.URL + "/images/t1"
+	setPollInterval(t, time.Millisecond)
+
+	e.handle("/imagetransfers", func(w http.ResponseWriter, r *http.Request) {
+		t := &ImageTransfer{}
+		xml.NewDecoder(r.Body).Decode(t)
+
+		f.mu.Lock()
+		f.created = t
+		f.mu.Unlock()
+
+		writeXML(w, http.StatusCreated, `<image_transfer id="t1"><phase>initializing</phase></image_transfer>`)
+	})
+	e.handle("/imagetransfers/t1", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+
+		f.polls++
+		switch {
+		case f.cancelled:
+			writeXML(w, http.StatusOK, `<image_transfer id="t1"><phase>finished_failure</phase></image_transfer>`)
+		case f.finalized:
+			writeXML(w, http.StatusOK, `<image_transfer id="t1"><phase>finished_success</phase></image_transfer>`)
+		case f.polls == 1:
+			writeXML(w, http.StatusOK, `<image_transfer id="t1"><phase>initializing</phase></image_transfer>`)
+		default:
+			writeXML(w, http.StatusOK, fmt.Sprintf(`<image_transfer id="t1"><phase>transferring</phase>`+
+				`<transfer_url>%s</transfer_url><proxy_url>%s</proxy_url></image_transfer>`, f.transferURL, f.proxyURL))
+		}
+	})
+	e.handle("/imagetransfers/t1/finalize", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		f.finalized = true
+		f.mu.Unlock()
+
+		writeXML(w, http.StatusOK, `<action><status>complete</status></action>`)
+	})
+	e.handle("/imagetransfers/t1/cancel", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		f.cancelled = true
+		f.mu.Unlock()
+
+		writeXML(w, http.StatusOK, `<action><status>complete</status></action>`)
+	})
+
+	return f
+}
+
+// serveDaemon writes uploaded ranges into the image and returns the image for downloads
+func (f *fakeImageTransfer) serveDaemon(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if r.Method == "GET" {
+		w.Write(f.image)
+		return
+	}
+
+	cr := r.Header.Get("Content-Range")
+	f.ranges = append(f.ranges, cr)
+
+	var start, end, size int64
+	_, err := fmt.Sscanf(cr, "bytes %d-%d/%d", &start, &end, &size)
+	if err != nil {
+		http.Error(w, "invalid range", http.StatusBadRequest)
+		return
+	}
+
+	b, err := io.ReadAll(r.Body)
+	if err != nil || int64(len(b)) != end-start+1 {
+		http.Error(w, "short body", http.StatusBadRequest)
+		return
+	}
+
+	if int64(len(f.image)) < size {
+		f.image = append(f.image, make([]byte, size-int64(len(f.image)))...)
+	}
+	copy(f.image[start:], b)
+}
+
+func (f *fakeImageTransfer) state() (finalized, cancelled bool, image []byte) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.finalized, f.cancelled, append([]byte(nil), f.image...)
+}
+
+// randomImage returns size bytes of random data
+func randomImage(size int) []byte {
+	b := make([]byte, size)
+	rand.New(rand.NewSource(1)).Read(b)
+	return b
+}
+
+// closedURL returns a URL nothing listens on
+func closedURL(t *testing.T) string {
+	s := httptest.NewServer(http.NotFoundHandler())
+	s.Close()
+
+	return s.URL
+}
+
+func TestUploadImage(t *testing.T) {
+	e := newTestEngine(t)
+	f := newFakeImageTransfer(t, e, nil)
+	c := e.client(t)
+
+	image := randomImage(imageChunkSize + 1000)
+	err := c.UploadImage("d1", bytes.NewReader(image), int64(len(image)))
+	if err != nil {
+		t.Fatalf("UploadImage: %v", err)
+	}
+
+	if f.created == nil || f.created.Direction != "upload" || f.created.Disk == nil || f.created.Disk.ID != "d1" {
+		t.Fatalf("unexpected transfer created: %+v", f.created)
+	}
+
+	finalized, cancelled, uploaded := f.state()
+	if !finalized || cancelled {
+		t.Fatalf("expected the transfer to be finalized, finalized: %t, cancelled: %t", finalized, cancelled)
+	}
+	if !bytes.Equal(uploaded, image) {
+		t.Fatal("uploaded image differs")
+	}
+
+	want := fmt.Sprintf("bytes 0-%d/%d,bytes %d-%d/%d", imageChunkSize-1, len(image), imageChunkSize, len(image)-1, len(image))
+	if got := strings.Join(f.ranges, ","); got != want {
+		t.Fatalf("expected the ranges %s, got %s", want, got)
+	}
+}
+
+func TestUploadImageProxyFallback(t *testing.T) {
+	e := newTestEngine(t)
+	f := newFakeImageTransfer(t, e, nil)
+	f.proxyURL = f.transferURL
+	f.transferURL = closedURL(t) + "/images/t1"
+	c := e.client(t)
+
+	image := randomImage(64 << 10)
+	err := c.UploadImage("d1", bytes.NewReader(image), int64(len(image)))
+	if err != nil {
+		t.Fatalf("UploadImage: %v", err)
+	}
+
+	finalized, _, uploaded := f.state()
+	if !finalized || !bytes.Equal(uploaded, image) {
+		t.Fatalf("expected the image to be uploaded through the proxy, finalized: %t", finalized)
+	}
+}
+
+func TestUploadImageNoFallbackAfterPartialRead(t *testing.T) {
+	e := newTestEngine(t)
+	f := newFakeImageTransfer(t, e, nil)
+
+	// the host drops the connection after reading a part of the data
+	host := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.CopyN(io.Discard, r.Body, 1024)
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err == nil {
+			conn.Close()
+		}
+	}))
+	t.Cleanup(host.Close)
+	f.proxyURL = f.transferURL
+	f.transferURL = host.URL + "/images/t1"
+	c := e.client(t)
+
+	image := randomImage(1 << 20)
+	err := c.UploadImage("d1", bytes.NewReader(image), int64(len(image)))
+	if err == nil {
+		t.Fatal("expected the upload to fail")
+	}
+
+	finalized, cancelled, _ := f.state()
+	if finalized || !cancelled {
+		t.Fatalf("expected the transfer to be cancelled, finalized: %t, cancelled: %t", finalized, cancelled)
+	}
+	if len(f.ranges) != 0 {
+		t.Fatalf("expected nothing to be sent to the proxy after data was read, got %v", f.ranges)
+	}
+}
+
+func TestUploadImageNoFallbackAfterConnect(t *testing.T) {
+	e := newTestEngine(t)
+	f := newFakeImageTransfer(t, e, nil)
+
+	// the host drops the connection without reading the body, which may have been sent already
+	host := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err == nil {
+			conn.Close()
+		}
+	}))
+	t.Cleanup(host.Close)
+	f.proxyURL = f.transferURL
+	f.transferURL = host.URL + "/images/t1"
+	c := e.client(t)
+
+	image := randomImage(64 << 10)
+	err := c.UploadImage("d1", bytes.NewReader(image), int64(len(image)))
+	if err == nil {
+		t.Fatal("expected the upload to fail")
+	}
+
+	finalized, cancelled, _ := f.state()
+	if finalized || !cancelled {
+		t.Fatalf("expected the transfer to be cancelled, finalized: %t, cancelled: %t", finalized, cancelled)
+	}
+	if len(f.ranges) != 0 {
+		t.Fatalf("expected nothing to be sent to the proxy after connecting to the host, got %v", f.ranges)
+	}
+}
+
+func TestDownloadImage(t *testing.T) {
+	e := newTestEngine(t)
+	image := randomImage(256 << 10)
+	f := newFakeImageTransfer(t, e, image)
+	c := e.client(t)
+
+	buf := &bytes.Buffer{}
+	err := c.DownloadImage("d1", buf)
+	if err != nil {
+		t.Fatalf("DownloadImage: %v", err)
+	}
+
+	if f.created == nil || f.created.Direction != "download" {
+		t.Fatalf("unexpected transfer created: %+v", f.created)
+	}
+	finalized, _, _ := f.state()
+	if !finalized || !bytes.Equal(buf.Bytes(), image) {
+		t.Fatalf("expected the image to be downloaded, finalized: %t", finalized)
+	}
+}
+
+func TestUploadImageContext(t *testing.T) {
+	e := newTestEngine(t)
+	setPollInterval(t, time.Millisecond)
+	cancelled := make(chan struct{})
+	e.handle("/imagetransfers", func(w http.ResponseWriter, r *http.Request) {
+		writeXML(w, http.StatusCreated, `<image_transfer id="t1"><phase>initializing</phase></image_transfer>`)
+	})
+	e.handle("/imagetransfers/t1", func(w http.ResponseWriter, r *http.Request) {
+		writeXML(w, http.StatusOK, `<image_transfer id="t1"><phase>initializing</phase></image_transfer>`)
+	})
+	e.handle("/imagetransfers/t1/cancel", func(w http.ResponseWriter, r *http.Request) {
+		close(cancelled)
+		writeXML(w, http.StatusOK, `<action><status>complete</status></action>`)
+	})
+	c := e.client(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	err := c.UploadImageContext(ctx, "d1", bytes.NewReader(randomImage(1000)), 1000)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the deadline to be exceeded while waiting for the transfer, got %v", err)
+	}
+
+	select {
+	case <-cancelled:
+	default:
+		t.Fatal("expected the transfer to be cancelled")
+	}
+}
@@ -0,0 +1,30 @@
+package api
+
+import "encoding/xml"
+
+// InstanceTypes is a collection of instance types as returned by the API
+type InstanceTypes struct {
+	InstanceTypes []InstanceType `xml:"instance_type"`
+}
+
+// InstanceType is a sizing template (memory, CPU) VMs can be based on
+type InstanceType struct {
+	XMLName     xml.Name `xml:"instance_type"`
+	ID          string   `xml:"id,attr,omitempty"`
+	Href        string   `xml:"href,attr,omitempty"`
+	Name        string   `xml:"name,omitempty"`
+	Description string   `xml:"description,omitempty"`
+	Memory      int64    `xml:"memory,omitempty"`
+	CPU         *CPU     `xml:"cpu,omitempty"`
+}
+
+// ListInstanceTypes retrieves all instance types defined in the engine
+func (c *Client) ListInstanceTypes() ([]InstanceType, error) {
+	res := &InstanceTypes{}
+	err := c.GetAndParse("/instancetypes", res)
+	if err != nil {
+		return nil, err
+	}
+
+	return res.InstanceTypes, nil
+}
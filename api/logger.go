@@ -14,19 +14,24 @@ type Logger interface {
 
 // DefaultLogger is the default impplemetation of Logger interface using golangs log package
 type defaultLogger struct {
+	debug bool
 }
 
 // Info implements Logger interfae
 func (l *defaultLogger) Infof(format string, args ...interface{}) {
-	log.Printf(format, args)
+	log.Printf(format, args...)
 }
 
 // Debug implements Logger interfae
 func (l *defaultLogger) Debugf(format string, args ...interface{}) {
-	log.Printf(format, args)
+	if !l.debug {
+		return
+	}
+
+	log.Printf(format, args...)
 }
 
 // Error implements Logger interfae
 func (l *defaultLogger) Errorf(format string, args ...interface{}) {
-	log.Printf(format, args)
+	log.Printf(format, args...)
 }
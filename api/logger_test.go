@@ -0,0 +1,106 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestRequestLogged(t *testing.T) {
+	e := newTestEngine(t)
+	e.handle("/vms", func(w http.ResponseWriter, r *http.Request) {
+		writeXML(w, http.StatusOK, "<vms/>")
+	})
+	l := &testLogger{}
+	c := e.client(t, WithLogger(l))
+
+	_, err := c.Get("/vms?max=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "GET " + e.apiURL() + "/vms?max=1"
+	if !strings.Contains(l.output(), want) {
+		t.Fatalf("expected %q in log output:\n%s", want, l.output())
+	}
+}
+
+func TestDefaultLoggerDebug(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log.SetOutput(buf)
+	defer log.SetOutput(os.Stderr)
+
+	(&defaultLogger{}).Debugf("hidden %d", 1)
+	(&defaultLogger{debug: true}).Debugf("shown %d", 2)
+	(&defaultLogger{}).Infof("info %d", 3)
+
+	out := buf.String()
+	if strings.Contains(out, "hidden") {
+		t.Fatalf("debug message logged without debug mode:\n%s", out)
+	}
+
+	if !strings.Contains(out, "shown 2") || !strings.Contains(out, "info 3") {
+		t.Fatalf("missing messages in log output:\n%s", out)
+	}
+}
+
+func TestCredentialsRedacted(t *testing.T) {
+	const password = "pa55-w0rd"
+
+	e := newTestEngine(t)
+	var requests int32
+	e.handle("/vms", func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) == 1 {
+			writeXML(w, http.StatusUnauthorized, "")
+			return
+		}
+		writeXML(w, http.StatusOK, "<vms/>")
+	})
+
+	l := &testLogger{}
+	var mu sync.Mutex
+	logged := &strings.Builder{}
+	c, err := NewClient(e.apiURL(), "user", password, WithDebug(), WithLogger(l), WithRequestLogger(func(info RequestInfo) {
+		mu.Lock()
+		fmt.Fprintf(logged, "%+v\n", info)
+		mu.Unlock()
+	}))
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	_, err = c.Get("/vms", Header("Cookie", "JSESSIONID=session-cookie"))
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+
+	mu.Lock()
+	output := l.output() + "\n" + logged.String()
+	mu.Unlock()
+	if !strings.Contains(output, "GET "+e.apiURL()+"/vms") || !strings.Contains(logged.String(), "Authorization:["+redacted+"]") {
+		t.Fatalf("expected the requests to be logged with redacted headers:\n%s", output)
+	}
+
+	// the rejected and the new token
+	for _, secret := range []string{password, "token-1", "token-2", "session-cookie"} {
+		if strings.Contains(output, secret) {
+			t.Errorf("%q logged:\n%s", secret, output)
+		}
+	}
+
+	// failed authentications do not report the form either
+	e.mux.HandleFunc("/failing/sso/oauth/token", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error":"access_denied","error_code":"invalid_grant"}`))
+	})
+	_, err = NewClient(e.apiURL(), "user", password, WithDebug(), WithLogger(l), WithSSOURL(e.URL+"/failing/sso/oauth/token"))
+	if err == nil || strings.Contains(err.Error(), password) || strings.Contains(l.output(), password) {
+		t.Fatalf("expected the authentication to fail without reporting the password, got %v", err)
+	}
+}
@@ -0,0 +1,64 @@
+package api
+
+import (
+	"encoding/xml"
+	"errors"
+)
+
+// MACPools is a collection of MAC address pools as returned by the API
+type MACPools struct {
+	MACPools []MACPool `xml:"mac_pool"`
+}
+
+// MACPool is a pool of MAC addresses assigned to the NICs of VMs
+type MACPool struct {
+	XMLName         xml.Name   `xml:"mac_pool"`
+	ID              string     `xml:"id,attr,omitempty"`
+	Href            string     `xml:"href,attr,omitempty"`
+	Name            string     `xml:"name,omitempty"`
+	Description     string     `xml:"description,omitempty"`
+	AllowDuplicates bool       `xml:"allow_duplicates"`
+	DefaultPool     bool       `xml:"default_pool"`
+	Ranges          []MACRange `xml:"ranges>range"`
+}
+
+// MACRange is a range of MAC addresses (both ends included)
+type MACRange struct {
+	From string `xml:"from"`
+	To   string `xml:"to"`
+}
+
+// ListMACPools retrieves all MAC address pools
+func (c *Client) ListMACPools() ([]MACPool, error) {
+	res := &MACPools{}
+	err := c.GetAndParse("/macpools", res)
+	if err != nil {
+		return nil, err
+	}
+
+	return res.MACPools, nil
+}
+
+// CreateMACPool creates a new MAC address pool
+func (c *Client) CreateMACPool(pool *MACPool) (*MACPool, error) {
+	if pool.Name == "" {
+		return nil, errors.New("mac pool name must not be empty")
+	}
+
+	if len(pool.Ranges) == 0 {
+		return nil, errors.New("mac pool needs at least one range")
+	}
+
+	res := &MACPool{}
+	err := c.SendObject("/macpools", "POST", pool, res)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// AssignMACPool makes the cluster allocate MAC addresses from the given pool
+func (c *Client) AssignMACPool(clusterID, poolID string) error {
+	return c.updateCluster(clusterID, &Cluster{MACPool: &Link{ID: poolID}})
+}
@@ -0,0 +1,51 @@
+package api
+
+// MaintenanceStatus describes the hosted engine HA state of the environment
+type MaintenanceStatus struct {
+	// GlobalMaintenance is true if any hosted engine host reports global maintenance
+	GlobalMaintenance bool
+
+	// HostedEngine is true if at least one host is configured to run the hosted engine
+	HostedEngine bool
+
+	// Hosts contains the HA state of every hosted engine host
+	Hosts []HostMaintenanceStatus
+}
+
+// HostMaintenanceStatus is the hosted engine HA state of a single host
+type HostMaintenanceStatus struct {
+	HostID   string
+	HostName string
+	HostedEngine
+}
+
+// IsGlobalMaintenance reports whether the hosted engine is in global maintenance.
+// The API exposes the state only on the hosts running hosted engine agents, so it is
+// derived from them. In environments without hosted engine the result is always false.
+func (c *Client) IsGlobalMaintenance() (bool, *MaintenanceStatus, error) {
+	hosts := &Hosts{}
+	err := c.GetAndParse("/hosts?all_content=true", hosts)
+	if err != nil {
+		return false, nil, err
+	}
+
+	status := &MaintenanceStatus{}
+	for _, h := range hosts.Hosts {
+		if h.HostedEngine == nil || !h.HostedEngine.Configured {
+			continue
+		}
+
+		status.HostedEngine = true
+		status.Hosts = append(status.Hosts, HostMaintenanceStatus{
+			HostID:       h.ID,
+			HostName:     h.Name,
+			HostedEngine: *h.HostedEngine,
+		})
+
+		if h.HostedEngine.GlobalMaintenance {
+			status.GlobalMaintenance = true
+		}
+	}
+
+	return status.GlobalMaintenance, status, nil
+}
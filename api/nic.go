@@ -0,0 +1,102 @@
+package api
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+)
+
+// NICs is a collection of network interfaces as returned by the API
+type NICs struct {
+	NICs []NIC `xml:"nic"`
+}
+
+// NIC represents a network interface of a VM.
+// Plugged and Linked are pointers so updates can distinguish false from unset.
+type NIC struct {
+	XMLName     xml.Name `xml:"nic"`
+	ID          string   `xml:"id,attr,omitempty"`
+	Href        string   `xml:"href,attr,omitempty"`
+	Name        string   `xml:"name,omitempty"`
+	Interface   string   `xml:"interface,omitempty"`
+	Plugged     *bool    `xml:"plugged,omitempty"`
+	Linked      *bool    `xml:"linked,omitempty"`
+	MAC         *MAC     `xml:"mac,omitempty"`
+	VnicProfile *Link    `xml:"vnic_profile,omitempty"`
+	Network     *Link    `xml:"network,omitempty"`
+	VM          *Link    `xml:"vm,omitempty"`
+}
+
+// MAC is the MAC address of a NIC
+type MAC struct {
+	Address string `xml:"address,omitempty"`
+}
+
+// VMNics retrieves the NICs of a VM
+func (c *Client) VMNics(vmID string) ([]NIC, error) {
+	res := &NICs{}
+	err := c.GetAndParse("/vms/"+vmID+"/nics", res)
+	if err != nil {
+		return nil, err
+	}
+
+	return res.NICs, nil
+}
+
+// GetNIC retrieves a NIC of a VM
+func (c *Client) GetNIC(vmID, nicID string) (*NIC, error) {
+	res := &NIC{}
+	err := c.GetAndParse("/vms/"+vmID+"/nics/"+nicID, res)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// UpdateNIC changes a NIC of a VM. Only the fields set in changes are sent.
+//
+// Unlike VM updates there is no next-run configuration for NICs, the engine applies all
+// changes to a running VM immediately:
+//   - Plugged hot plugs or unplugs the NIC
+//   - Linked connects or disconnects the virtual cable
+//   - VnicProfile switches the network of the NIC; the profile is checked to exist first
+//   - Name can be changed at any time
+//   - Interface and MAC can only be changed while the NIC is unplugged, so changing them
+//     on a plugged NIC requires unplugging it in the same update (Plugged set to false)
+func (c *Client) UpdateNIC(vmID, nicID string, changes *NIC) (*NIC, error) {
+	if changes.VnicProfile != nil && changes.VnicProfile.ID != "" {
+		err := c.GetAndParse("/vnicprofiles/"+changes.VnicProfile.ID, &struct {
+			XMLName xml.Name `xml:"vnic_profile"`
+		}{})
+		if err != nil {
+			return nil, fmt.Errorf("vnic profile %s: %v", changes.VnicProfile.ID, err)
+		}
+	}
+
+	changesHardware := changes.Interface != "" || (changes.MAC != nil && changes.MAC.Address != "")
+	if changesHardware && (changes.Plugged == nil || *changes.Plugged) {
+		current, err := c.GetNIC(vmID, nicID)
+		if err != nil {
+			return nil, err
+		}
+
+		if current.Plugged != nil && *current.Plugged {
+			if changes.Interface != "" && current.Interface != changes.Interface {
+				return nil, errors.New("interface type of a plugged nic can not be changed, unplug it first")
+			}
+
+			if changes.MAC != nil && changes.MAC.Address != "" && (current.MAC == nil || current.MAC.Address != changes.MAC.Address) {
+				return nil, errors.New("mac address of a plugged nic can not be changed, unplug it first")
+			}
+		}
+	}
+
+	res := &NIC{}
+	err := c.SendObject("/vms/"+vmID+"/nics/"+nicID, "PUT", changes, res)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
@@ -0,0 +1,129 @@
+package api
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestUpdateNIC(t *testing.T) {
+	e := newTestEngine(t)
+	puts := 0
+	e.handle("/vms/1/nics/2", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "PUT" {
+			puts++
+			b, _ := io.ReadAll(r.Body)
+			writeXML(w, http.StatusOK, string(b))
+			return
+		}
+
+		writeXML(w, http.StatusOK, `<nic id="2"><interface>virtio</interface><plugged>true</plugged><mac><address>56:6f:00:00:00:01</address></mac></nic>`)
+	})
+	e.handle("/vnicprofiles/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != testAPIPath+"/vnicprofiles/p1" {
+			writeXML(w, http.StatusNotFound, "<fault><reason>Not Found</reason></fault>")
+			return
+		}
+
+		writeXML(w, http.StatusOK, `<vnic_profile id="p1"/>`)
+	})
+	c := e.client(t)
+
+	plugged, unplugged := true, false
+	tests := []struct {
+		name    string
+		changes *NIC
+		err     string
+	}{
+		{"switch profile", &NIC{VnicProfile: &Link{ID: "p1"}}, ""},
+		{"unknown profile", &NIC{VnicProfile: &Link{ID: "missing"}}, "vnic profile missing"},
+		{"interface of plugged nic", &NIC{Interface: "e1000"}, "interface type"},
+		{"mac of plugged nic", &NIC{MAC: &MAC{Address: "56:6f:00:00:00:02"}}, "mac address"},
+		{"interface with unplug", &NIC{Interface: "e1000", Plugged: &unplugged}, ""},
+		{"interface staying plugged", &NIC{Interface: "e1000", Plugged: &plugged}, "interface type"},
+		{"unchanged interface", &NIC{Interface: "virtio"}, ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			before := puts
+			_, err := c.UpdateNIC("1", "2", tc.changes)
+			if tc.err == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if puts != before+1 {
+					t.Fatal("nic was not updated")
+				}
+				return
+			}
+
+			if err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Fatalf("expected error containing %q, got %v", tc.err, err)
+			}
+			if puts != before {
+				t.Fatal("nic was updated although the change was rejected")
+			}
+		})
+	}
+}
+
+// vmNICs is a nics sub collection as returned by the engine
+const vmNICs = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<nics>
+  <nic href="/ovirt-engine/api/vms/1/nics/a1" id="a1">
+    <name>nic1</name>
+    <interface>virtio</interface>
+    <linked>true</linked>
+    <mac>
+      <address>56:6f:1a:2b:00:01</address>
+    </mac>
+    <plugged>true</plugged>
+    <network href="/ovirt-engine/api/networks/n1" id="n1"/>
+    <vnic_profile href="/ovirt-engine/api/vnicprofiles/p1" id="p1"/>
+    <vm href="/ovirt-engine/api/vms/1" id="1"/>
+  </nic>
+  <nic href="/ovirt-engine/api/vms/1/nics/a2" id="a2">
+    <name>nic2</name>
+    <interface>e1000</interface>
+    <linked>false</linked>
+    <mac>
+      <address>56:6f:1a:2b:00:02</address>
+    </mac>
+    <plugged>false</plugged>
+    <vm href="/ovirt-engine/api/vms/1" id="1"/>
+  </nic>
+</nics>`
+
+func TestVMNics(t *testing.T) {
+	e := newTestEngine(t)
+	e.handle("/vms/1/nics", func(w http.ResponseWriter, r *http.Request) {
+		writeXML(w, http.StatusOK, vmNICs)
+	})
+	c := e.client(t)
+
+	nics, err := c.VMNics("1")
+	if err != nil {
+		t.Fatalf("VMNics: %v", err)
+	}
+	if len(nics) != 2 {
+		t.Fatalf("expected 2 nics, got %d", len(nics))
+	}
+
+	n := nics[0]
+	if n.ID != "a1" || n.Name != "nic1" || n.Interface != "virtio" || n.Linked == nil || !*n.Linked || n.MAC == nil || n.MAC.Address != "56:6f:1a:2b:00:01" {
+		t.Fatalf("unexpected nic %+v", n)
+	}
+	if n.Network == nil || n.Network.ID != "n1" || n.Network.Href != "/ovirt-engine/api/networks/n1" {
+		t.Fatalf("expected the network link, got %+v", n.Network)
+	}
+	if n.VnicProfile == nil || n.VnicProfile.ID != "p1" || n.VM == nil || n.VM.ID != "1" {
+		t.Fatalf("unexpected links %+v, %+v", n.VnicProfile, n.VM)
+	}
+
+	n = nics[1]
+	if n.Linked == nil || *n.Linked || n.Plugged == nil || *n.Plugged || n.Network != nil {
+		t.Fatalf("unexpected unlinked nic %+v", n)
+	}
+}
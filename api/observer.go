@@ -0,0 +1,53 @@
+package api
+
+import "time"
+
+// Observer is notified about every request sent to the API, e.g. to export metrics.
+// Repeated requests (after a rejected token or a transient failure) are observed individually
+// with their kind, so a reauthentication shows up as a FirstAttempt with status 401 followed by
+// a ReauthAttempt. Implementations must be safe for concurrent use.
+type Observer interface {
+	// ObserveRequest is called for each completed request. status is 0 if no response was received.
+	ObserveRequest(method string, status int, dur time.Duration, kind RequestKind)
+}
+
+// RequestKind tells why a request was sent, e.g. to use it as metrics label
+type RequestKind int
+
+const (
+	// FirstAttempt is the first time a request is sent
+	FirstAttempt RequestKind = iota
+	// RetryAttempt is a request sent again after a transient failure (see WithRetry)
+	RetryAttempt
+	// ReauthAttempt is a request sent again with a new token after the previous one was rejected
+	ReauthAttempt
+)
+
+func (k RequestKind) String() string {
+	switch k {
+	case FirstAttempt:
+		return "first"
+	case RetryAttempt:
+		return "retry"
+	case ReauthAttempt:
+		return "reauth"
+	}
+
+	return "unknown"
+}
+
+// WithObserver reports all requests to o (nil disables the observer)
+func WithObserver(o Observer) ClientOption {
+	return func(c *Client) {
+		if o == nil {
+			o = nopObserver{}
+		}
+
+		c.observer = o
+	}
+}
+
+// nopObserver is the default observer discarding all observations
+type nopObserver struct{}
+
+func (nopObserver) ObserveRequest(method string, status int, dur time.Duration, kind RequestKind) {}
@@ -0,0 +1,93 @@
+package api
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// recordingObserver records the observations as "method status kind"
+type recordingObserver struct {
+	mu           sync.Mutex
+	observations []string
+}
+
+func (o *recordingObserver) ObserveRequest(method string, status int, dur time.Duration, kind RequestKind) {
+	o.mu.Lock()
+	o.observations = append(o.observations, fmt.Sprintf("%s %d %s", method, status, kind))
+	o.mu.Unlock()
+}
+
+func (o *recordingObserver) recorded() string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	return strings.Join(o.observations, ", ")
+}
+
+func TestWithObserver(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		opts   []ClientOption
+		want   string
+	}{
+		{"reauth", http.StatusUnauthorized, nil, "GET 401 first, GET 200 reauth"},
+		{"retry", http.StatusServiceUnavailable, []ClientOption{WithRetry(3, time.Millisecond)}, "GET 503 first, GET 200 retry"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			e := newTestEngine(t)
+			var requests int32
+			e.handle("/vms", func(w http.ResponseWriter, r *http.Request) {
+				if atomic.AddInt32(&requests, 1) == 1 {
+					writeXML(w, tc.status, "")
+					return
+				}
+				writeXML(w, http.StatusOK, "<vms/>")
+			})
+
+			o := &recordingObserver{}
+			c := e.client(t, append([]ClientOption{WithObserver(o)}, tc.opts...)...)
+
+			_, err := c.Get("/vms")
+			if err != nil {
+				t.Fatalf("Get: %v", err)
+			}
+			if got := o.recorded(); got != tc.want {
+				t.Fatalf("expected the observations %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestStreamObserver(t *testing.T) {
+	e := newTestEngine(t)
+	var requests int32
+	e.handle("/events", func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) == 1 {
+			writeXML(w, http.StatusUnauthorized, "")
+			return
+		}
+		writeXML(w, http.StatusOK, "<events/>")
+	})
+
+	o := &recordingObserver{}
+	c := e.client(t, WithObserver(o))
+
+	err := c.Stream("/events", func(d *xml.Decoder) error {
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Stream: %v", err)
+	}
+	if got, want := o.recorded(), "GET 401 first, GET 200 reauth"; got != want {
+		t.Fatalf("expected the observations %q, got %q", want, got)
+	}
+}
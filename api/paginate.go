@@ -0,0 +1,174 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+)
+
+// defaultPageSize is the number of items GetAll requests per page
+const defaultPageSize = 100
+
+// PageIterator walks a collection page by page using the page search token of the engine
+type PageIterator struct {
+	client   *Client
+	path     string
+	query    string
+	pageSize int
+	page     int
+	done     bool
+}
+
+// Paginate returns an iterator over the collection at path. query is an optional search
+// query (a sortby clause keeps the order stable between pages), pageSize the number of items per page.
+func (c *Client) Paginate(path, query string, pageSize int) *PageIterator {
+	return &PageIterator{
+		client:   c,
+		path:     path,
+		query:    query,
+		pageSize: pageSize,
+		page:     1,
+	}
+}
+
+// ResumePaginate continues an iteration from a state previously returned by State
+func (c *Client) ResumePaginate(state string) (*PageIterator, error) {
+	v, err := url.ParseQuery(state)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pagination state: %v", err)
+	}
+
+	it := c.Paginate(v.Get("path"), v.Get("search"), 0)
+	it.pageSize, err = strconv.Atoi(v.Get("max"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid pagination state: %v", err)
+	}
+
+	it.page, err = strconv.Atoi(v.Get("page"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid pagination state: %v", err)
+	}
+
+	it.done = v.Get("done") == "true"
+	if it.path == "" || it.pageSize <= 0 || it.page <= 0 {
+		return nil, errors.New("invalid pagination state")
+	}
+
+	return it, nil
+}
+
+// State returns the position of the iterator as string, which can be persisted and passed to ResumePaginate
+func (it *PageIterator) State() string {
+	v := url.Values{}
+	v.Set("path", it.path)
+	v.Set("search", it.query)
+	v.Set("max", strconv.Itoa(it.pageSize))
+	v.Set("page", strconv.Itoa(it.page))
+	if it.done {
+		v.Set("done", "true")
+	}
+
+	return v.Encode()
+}
+
+// Next retrieves the next page into v, which must be a pointer to a collection struct (e.g. *VMs).
+// The content of v is replaced on each call. It returns false when there are no more pages.
+func (it *PageIterator) Next(v interface{}) (bool, error) {
+	if it.done {
+		return false, nil
+	}
+
+	if it.pageSize <= 0 {
+		return false, errors.New("page size must be greater than 0")
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
+		return false, errors.New("collection must be a pointer to a struct")
+	}
+	rv.Elem().Set(reflect.Zero(rv.Elem().Type()))
+
+	err := it.client.GetAndParse(it.pagePath(), v)
+	if err != nil {
+		return false, err
+	}
+
+	n, err := countItems(v)
+	if err != nil {
+		return false, err
+	}
+
+	it.page++
+	if n < it.pageSize {
+		it.done = true
+	}
+
+	return n > 0, nil
+}
+
+func (it *PageIterator) pagePath() string {
+	q := "page " + strconv.Itoa(it.page)
+	if it.query != "" {
+		q = it.query + " " + q
+	}
+
+	return searchPath(it.path, q) + "&max=" + strconv.Itoa(it.pageSize)
+}
+
+// GetAll retrieves all items of the collection at path page by page and accumulates them into v,
+// which must be a pointer to a collection struct (e.g. *VMs). query is an optional search query.
+func (c *Client) GetAll(path, query string, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
+		return errors.New("collection must be a pointer to a struct")
+	}
+
+	items, err := sliceField(rv.Elem())
+	if err != nil {
+		return err
+	}
+
+	it := c.Paginate(path, query, defaultPageSize)
+	page := reflect.New(rv.Elem().Type())
+	for {
+		ok, err := it.Next(page.Interface())
+		if err != nil {
+			return err
+		}
+
+		if !ok {
+			return nil
+		}
+
+		pageItems, _ := sliceField(page.Elem())
+		items.Set(reflect.AppendSlice(items, pageItems))
+	}
+}
+
+// sliceField returns the first slice field of the struct v
+func sliceField(v reflect.Value) (reflect.Value, error) {
+	for i := 0; i < v.NumField(); i++ {
+		if v.Field(i).Kind() == reflect.Slice {
+			return v.Field(i), nil
+		}
+	}
+
+	return reflect.Value{}, fmt.Errorf("%s has no slice field", v.Type())
+}
+
+// countItems returns the length of the first slice field of the struct v points to
+func countItems(v interface{}) (int, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
+		return 0, errors.New("collection must be a pointer to a struct")
+	}
+
+	items, err := sliceField(rv.Elem())
+	if err != nil {
+		return 0, err
+	}
+
+	return items.Len(), nil
+}
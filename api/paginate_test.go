@@ -0,0 +1,97 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+var pagePattern = regexp.MustCompile(`page (\d+)`)
+
+// pagedVMs serves total VMs page by page
+func pagedVMs(total int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		m := pagePattern.FindStringSubmatch(r.URL.Query().Get("search"))
+		max, err := strconv.Atoi(r.URL.Query().Get("max"))
+		if m == nil || err != nil {
+			writeXML(w, http.StatusBadRequest, "<fault><reason>missing page or max</reason></fault>")
+			return
+		}
+		page, _ := strconv.Atoi(m[1])
+
+		b := &strings.Builder{}
+		b.WriteString("<vms>")
+		for i := (page - 1) * max; i < page*max && i < total; i++ {
+			fmt.Fprintf(b, `<vm id="%d"><name>vm%d</name></vm>`, i, i)
+		}
+		b.WriteString("</vms>")
+
+		writeXML(w, http.StatusOK, b.String())
+	}
+}
+
+func TestGetAll(t *testing.T) {
+	for _, total := range []int{0, 1, defaultPageSize, defaultPageSize + 1, 2*defaultPageSize + 5} {
+		t.Run(strconv.Itoa(total), func(t *testing.T) {
+			e := newTestEngine(t)
+			e.handle("/vms", pagedVMs(total))
+			c := e.client(t)
+
+			vms := &VMs{}
+			err := c.GetAll("/vms", "sortby name asc", vms)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(vms.VMs) != total {
+				t.Fatalf("expected %d vms, got %d", total, len(vms.VMs))
+			}
+
+			for i, vm := range vms.VMs {
+				if vm.ID != strconv.Itoa(i) {
+					t.Fatalf("expected vm %d at position %d, got %s", i, i, vm.ID)
+				}
+			}
+		})
+	}
+}
+
+func TestPaginateResume(t *testing.T) {
+	e := newTestEngine(t)
+	e.handle("/vms", pagedVMs(5))
+	c := e.client(t)
+
+	it := c.Paginate("/vms", "", 2)
+	vms := &VMs{}
+	ok, err := it.Next(vms)
+	if err != nil || !ok || len(vms.VMs) != 2 {
+		t.Fatalf("unexpected first page: %v %v %+v", ok, err, vms)
+	}
+
+	it, err = c.ResumePaginate(it.State())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ids := []string{}
+	for {
+		ok, err := it.Next(vms)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ok {
+			break
+		}
+
+		for _, vm := range vms.VMs {
+			ids = append(ids, vm.ID)
+		}
+	}
+
+	if strings.Join(ids, ",") != "2,3,4" {
+		t.Fatalf("expected the remaining vms 2,3,4, got %v", ids)
+	}
+}
@@ -0,0 +1,25 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+)
+
+// Ping checks that the engine is reachable and accepts the token of the client by retrieving the
+// entry point of the API without parsing it. A rejected token is reauthenticated once; if the
+// engine still answers with 401 the error wraps ErrReauthFailed. Network errors are wrapped as well.
+func (c *Client) Ping(ctx context.Context) error {
+	_, err := c.sendRequest(ctx, "/", "GET", nil, true, nil)
+	if err == nil {
+		return nil
+	}
+
+	var urlErr *url.Error
+	if ctx.Err() == nil && errors.As(err, &urlErr) {
+		return fmt.Errorf("engine is not reachable: %w", err)
+	}
+
+	return err
+}
@@ -0,0 +1,49 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestPing(t *testing.T) {
+	e := newTestEngine(t)
+	e.handleAPIDocument()
+	c := e.client(t)
+
+	err := c.Ping(context.Background())
+	if err != nil {
+		t.Fatalf("expected a healthy engine, got %v", err)
+	}
+}
+
+func TestPingUnauthorized(t *testing.T) {
+	e := newTestEngine(t)
+	e.handle("/", func(w http.ResponseWriter, r *http.Request) {
+		writeXML(w, http.StatusUnauthorized, "")
+	})
+	c := e.client(t)
+
+	err := c.Ping(context.Background())
+	if !errors.Is(err, ErrReauthFailed) || !isStatus(err, http.StatusUnauthorized) {
+		t.Fatalf("expected ErrReauthFailed wrapping the 401, got %v", err)
+	}
+	if n := e.tokens(); n != 2 {
+		t.Fatalf("expected a single reauthentication, got %d token requests", n)
+	}
+}
+
+func TestPingUnreachable(t *testing.T) {
+	e := newTestEngine(t)
+	c := e.client(t)
+	e.Close()
+
+	err := c.Ping(context.Background())
+	var urlErr *url.Error
+	if !errors.As(err, &urlErr) || !strings.HasPrefix(err.Error(), "engine is not reachable: ") {
+		t.Fatalf("expected a wrapped network error, got %v", err)
+	}
+}
@@ -0,0 +1,78 @@
+package api
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// WithRateLimit limits the requests sent to the engine to rps per second on average, allowing
+// bursts of up to burst requests. Requests wait until they may be sent or their context is done.
+// The limit is shared by all goroutines using the client and its clones.
+func WithRateLimit(rps float64, burst int) ClientOption {
+	return func(c *Client) {
+		c.rateLimiter = newRateLimiter(rps, burst)
+	}
+}
+
+// rateLimiter is a token bucket refilled with rps tokens per second
+type rateLimiter struct {
+	rps   float64
+	burst float64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+func newRateLimiter(rps float64, burst int) *rateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &rateLimiter{rps: rps, burst: float64(burst), tokens: float64(burst)}
+}
+
+// wait takes a token, waiting until one is available. The token is returned if ctx is done
+// before, or would be done before the token is available.
+func (l *rateLimiter) wait(ctx context.Context) error {
+	if l == nil || l.rps <= 0 {
+		return nil
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	if !l.last.IsZero() {
+		l.tokens += now.Sub(l.last).Seconds() * l.rps
+		if l.tokens > l.burst {
+			l.tokens = l.burst
+		}
+	}
+	l.last = now
+	l.tokens--
+	d := time.Duration(0)
+	if l.tokens < 0 {
+		d = time.Duration(-l.tokens / l.rps * float64(time.Second))
+	}
+	l.mu.Unlock()
+
+	if d == 0 {
+		return nil
+	}
+
+	err := ctx.Err()
+	if deadline, ok := ctx.Deadline(); ok && err == nil && now.Add(d).After(deadline) {
+		err = context.DeadlineExceeded
+	}
+	if err == nil {
+		err = sleepContext(ctx, d)
+	}
+
+	if err != nil {
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+	}
+
+	return err
+}
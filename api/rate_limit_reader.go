@@ -0,0 +1,70 @@
+package api
+
+import (
+	"context"
+	"io"
+	"sync/atomic"
+	"time"
+)
+
+// WithTransferRateLimit caps the throughput of image uploads and downloads in bytes per second.
+// A limit of 0 (the default) disables throttling.
+func WithTransferRateLimit(bytesPerSecond int64) ClientOption {
+	return func(c *Client) {
+		c.SetTransferRateLimit(bytesPerSecond)
+	}
+}
+
+// SetTransferRateLimit changes the transfer rate limit, also for transfers already running
+func (c *Client) SetTransferRateLimit(bytesPerSecond int64) {
+	atomic.StoreInt64(&c.transferRate, bytesPerSecond)
+}
+
+func (c *Client) transferRateLimit() int64 {
+	return atomic.LoadInt64(&c.transferRate)
+}
+
+// rateLimitedReader delays reads so the average throughput stays below the current limit
+type rateLimitedReader struct {
+	ctx   context.Context
+	r     io.Reader
+	limit func() int64
+
+	rate  int64
+	start time.Time
+	n     int64
+}
+
+func newRateLimitedReader(ctx context.Context, r io.Reader, limit func() int64) io.Reader {
+	return &rateLimitedReader{ctx: ctx, r: r, limit: limit}
+}
+
+func (l *rateLimitedReader) Read(p []byte) (int, error) {
+	rate := l.limit()
+	if rate <= 0 {
+		return l.r.Read(p)
+	}
+
+	if rate != l.rate {
+		l.rate = rate
+		l.start = time.Now()
+		l.n = 0
+	}
+
+	// never read more than one second worth of data at once
+	if int64(len(p)) > rate {
+		p = p[:rate]
+	}
+
+	n, err := l.r.Read(p)
+	l.n += int64(n)
+
+	expected := time.Duration(float64(l.n) / float64(rate) * float64(time.Second))
+	if wait := expected - time.Since(l.start); wait > 0 {
+		if serr := sleepContext(l.ctx, wait); serr != nil {
+			return n, serr
+		}
+	}
+
+	return n, err
+}
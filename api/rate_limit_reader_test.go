@@ -0,0 +1,91 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRateLimitedReader(t *testing.T) {
+	data := randomImage(30 << 10)
+	limit := int64(100 << 10)
+	r := newRateLimitedReader(context.Background(), bytes.NewReader(data), func() int64 { return limit })
+
+	start := time.Now()
+	b, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	d := time.Since(start)
+
+	if !bytes.Equal(b, data) {
+		t.Fatal("expected the data to be passed through")
+	}
+	// 30 KiB at 100 KiB/s
+	if d < 280*time.Millisecond || d > time.Second {
+		t.Fatalf("expected the read to take about 300ms, took %s", d)
+	}
+}
+
+func TestRateLimitedReaderChangedLimit(t *testing.T) {
+	var limit int64 = 10 << 10
+	r := newRateLimitedReader(context.Background(), bytes.NewReader(randomImage(100<<10)), func() int64 {
+		return atomic.LoadInt64(&limit)
+	})
+
+	start := time.Now()
+	buf := make([]byte, 2<<10)
+	_, err := io.ReadFull(r, buf)
+	if err != nil {
+		t.Fatalf("ReadFull: %v", err)
+	}
+
+	// lifting the limit lets the rest of the data pass at once
+	atomic.StoreInt64(&limit, 0)
+	_, err = io.Copy(io.Discard, r)
+	if err != nil {
+		t.Fatalf("Copy: %v", err)
+	}
+
+	// 2 KiB at 10 KiB/s, the remaining 98 KiB are not throttled
+	if d := time.Since(start); d < 180*time.Millisecond || d > time.Second {
+		t.Fatalf("expected the read to take about 200ms, took %s", d)
+	}
+}
+
+func TestRateLimitedReaderCanceled(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	r := newRateLimitedReader(ctx, bytes.NewReader(randomImage(10<<10)), func() int64 { return 1 << 10 })
+	_, err := io.ReadAll(r)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the throttled read to stop with the context, got %v", err)
+	}
+}
+
+func TestDownloadImageTransferRateLimit(t *testing.T) {
+	e := newTestEngine(t)
+	image := randomImage(50 << 10)
+	newFakeImageTransfer(t, e, image)
+	c := e.client(t, WithTransferRateLimit(200<<10))
+
+	start := time.Now()
+	buf := &bytes.Buffer{}
+	err := c.DownloadImage("d1", buf)
+	if err != nil {
+		t.Fatalf("DownloadImage: %v", err)
+	}
+
+	if !bytes.Equal(buf.Bytes(), image) {
+		t.Fatal("expected the image to be downloaded")
+	}
+	// 50 KiB at 200 KiB/s
+	if d := time.Since(start); d < 240*time.Millisecond {
+		t.Fatalf("expected the download to be throttled to 200 KiB/s, took %s", d)
+	}
+}
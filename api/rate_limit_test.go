@@ -0,0 +1,72 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWithRateLimit(t *testing.T) {
+	e := newTestEngine(t)
+	e.handle("/vms", func(w http.ResponseWriter, r *http.Request) {
+		writeXML(w, http.StatusOK, "<vms/>")
+	})
+	c := e.client(t, WithRateLimit(5, 1))
+
+	// the first request uses the burst, the other 9 wait 200ms each
+	const n = 10
+	min := time.Duration(n-1) * time.Second / 5
+
+	start := time.Now()
+	wg := sync.WaitGroup{}
+	errs := make(chan error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			_, err := c.Get("/vms")
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if d := time.Since(start); d < min {
+		t.Fatalf("expected %d requests at 5 rps to take at least %s, took %s", n, min, d)
+	}
+}
+
+func TestRateLimitCancelled(t *testing.T) {
+	e := newTestEngine(t)
+	e.handle("/vms", func(w http.ResponseWriter, r *http.Request) {
+		writeXML(w, http.StatusOK, "<vms/>")
+	})
+	c := e.client(t, WithRateLimit(0.1, 1))
+
+	_, err := c.Get("/vms")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err = c.GetContext(ctx, "/vms")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Fatalf("expected the request to give up when the context is done, waited %s", d)
+	}
+}
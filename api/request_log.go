@@ -0,0 +1,84 @@
+package api
+
+import (
+	"net/http"
+	"time"
+)
+
+// RequestInfo describes a request sent to the API and its outcome
+type RequestInfo struct {
+	Method string
+	URL    string
+
+	// Header contains the request headers with the credentials redacted
+	Header http.Header
+
+	// StatusCode is 0 if no response was received
+	StatusCode int
+	Duration   time.Duration
+
+	// Kind tells if the request was sent for the first time, retried or repeated after a reauthentication
+	Kind RequestKind
+
+	// RequestBytes and ResponseBytes are the sizes of the uncompressed request and the response body
+	RequestBytes  int
+	ResponseBytes int
+
+	// Err is the error sending the request or reading the response (not an error status)
+	Err error
+}
+
+// WithRequestLogger calls log after every request sent to the API (including requests repeated
+// after a reauthentication or a retry, see Kind), e.g. to emit structured logs or metrics.
+// log is called synchronously, so it must be fast and safe for concurrent use.
+func WithRequestLogger(log func(info RequestInfo)) ClientOption {
+	return func(c *Client) {
+		c.requestLogger = log
+	}
+}
+
+// requestDone passes the details of a finished request to the observer and the request logger.
+// resp is nil if no response was received.
+func (c *Client) requestDone(req *http.Request, kind RequestKind, resp *http.Response, requestBytes, responseBytes int, start time.Time, err error) {
+	dur := time.Since(start)
+	status := 0
+	if resp != nil {
+		status = resp.StatusCode
+	}
+
+	c.observer.ObserveRequest(req.Method, status, dur, kind)
+
+	if c.requestLogger == nil {
+		return
+	}
+
+	c.requestLogger(RequestInfo{
+		Method:        req.Method,
+		URL:           req.URL.String(),
+		Header:        redactHeader(req.Header),
+		StatusCode:    status,
+		Duration:      dur,
+		Kind:          kind,
+		RequestBytes:  requestBytes,
+		ResponseBytes: responseBytes,
+		Err:           err,
+	})
+}
+
+// redacted replaces credentials in logged requests
+const redacted = "***"
+
+// credentialHeaders are the headers redacted from logged requests
+var credentialHeaders = []string{"Authorization", "Cookie", "Proxy-Authorization"}
+
+// redactHeader returns a copy of h without the values of credential headers
+func redactHeader(h http.Header) http.Header {
+	res := h.Clone()
+	for _, k := range credentialHeaders {
+		if res.Get(k) != "" {
+			res.Set(k, redacted)
+		}
+	}
+
+	return res
+}
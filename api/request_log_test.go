@@ -0,0 +1,52 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWithRequestLogger(t *testing.T) {
+	e := newTestEngine(t)
+	e.handle("/vms", func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(time.Millisecond)
+		writeXML(w, http.StatusCreated, `<vm id="1"/>`)
+	})
+
+	var mu sync.Mutex
+	var infos []RequestInfo
+	c := e.client(t, WithRequestLogger(func(info RequestInfo) {
+		mu.Lock()
+		infos = append(infos, info)
+		mu.Unlock()
+	}))
+
+	body := `<vm><name>web01</name></vm>`
+	_, err := c.Post("/vms", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("Post: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if len(infos) != 1 {
+		t.Fatalf("expected one request to be logged, got %d", len(infos))
+	}
+
+	info := infos[0]
+	if info.Method != "POST" || info.URL != e.apiURL()+"/vms" || info.StatusCode != http.StatusCreated {
+		t.Fatalf("unexpected request %s %s: %d", info.Method, info.URL, info.StatusCode)
+	}
+	if info.Duration <= 0 {
+		t.Fatalf("expected a duration, got %s", info.Duration)
+	}
+	if info.RequestBytes != len(body) || info.ResponseBytes != len(`<vm id="1"/>`) || info.Err != nil {
+		t.Fatalf("unexpected sizes %d/%d (%v)", info.RequestBytes, info.ResponseBytes, info.Err)
+	}
+	if got := info.Header.Get("Authorization"); got != redacted {
+		t.Fatalf("expected the Authorization header to be redacted, got %q", got)
+	}
+}
@@ -0,0 +1,50 @@
+package api
+
+import (
+	"mime"
+	"net/http"
+)
+
+// RequestOption modifies a single request before it is sent. Options are applied after
+// the headers set by the client, so they can override them.
+type RequestOption func(*http.Request)
+
+// Header sets a header of a single request (e.g. If-Match), replacing the value set by the client
+func Header(key, value string) RequestOption {
+	return func(req *http.Request) {
+		req.Header.Set(key, value)
+	}
+}
+
+// AllContent requests the full representation of entities including sub elements
+// the engine omits by default (e.g. the initialization of a VM)
+func AllContent() RequestOption {
+	return func(req *http.Request) {
+		req.Header.Set("All-Content", "true")
+	}
+}
+
+// JSON requests the response as JSON, which SendAndParse and GetAndParse then decode with
+// encoding/json. The JSON representation has a different shape than the XML one (e.g. a collection
+// is an object like {"vm": [...]} and attributes like id are plain fields), so the XML models of
+// this package can not be used to decode it.
+func JSON() RequestOption {
+	return func(req *http.Request) {
+		req.Header.Set("Accept", "application/json")
+	}
+}
+
+// isJSON reports if the content type of a response is JSON
+func isJSON(h http.Header) bool {
+	ct, _, _ := mime.ParseMediaType(h.Get("Content-Type"))
+	return ct == "application/json"
+}
+
+// ExpectCreated sends "Expect: 201-created", so the engine answers a create request only after the
+// entity was fully created (e.g. a disk is no longer locked) instead of returning early.
+// It replaces an "Expect: 100-continue" header set by WithExpectContinue.
+func ExpectCreated() RequestOption {
+	return func(req *http.Request) {
+		req.Header.Set("Expect", "201-created")
+	}
+}
@@ -0,0 +1,163 @@
+package api
+
+import (
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAllContent(t *testing.T) {
+	e := newTestEngine(t)
+	hr := &headerRecorder{body: "<vms/>"}
+	e.handle("/vms", hr.ServeHTTP)
+	c := e.client(t)
+
+	_, err := c.Get("/vms", AllContent())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := hr.last().Get("All-Content"); got != "true" {
+		t.Fatalf("expected All-Content: true, got %q", got)
+	}
+
+	_, err = c.Get("/vms")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := hr.last().Get("All-Content"); got != "" {
+		t.Fatalf("All-Content sent with a request without the option: %q", got)
+	}
+}
+
+// vmsJSON is a (shortened) vms collection as returned by the engine with Accept: application/json
+const vmsJSON = `{
+  "vm": [
+    {
+      "name": "web01",
+      "status": "up",
+      "memory": "4294967296",
+      "href": "/ovirt-engine/api/vms/3f3e6a4c-0c5e-4b1c-9d5d-2f6a3b1c0a11",
+      "id": "3f3e6a4c-0c5e-4b1c-9d5d-2f6a3b1c0a11"
+    },
+    {
+      "name": "db01",
+      "status": "down",
+      "memory": "8589934592",
+      "href": "/ovirt-engine/api/vms/9a1b2c3d-4e5f-4a6b-8c7d-0e1f2a3b4c5d",
+      "id": "9a1b2c3d-4e5f-4a6b-8c7d-0e1f2a3b4c5d"
+    }
+  ]
+}`
+
+func TestJSON(t *testing.T) {
+	e := newTestEngine(t)
+	e.handle("/vms", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept") == "application/json" {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(vmsJSON))
+			return
+		}
+		writeXML(w, http.StatusOK, vmsListing)
+	})
+	c := e.client(t)
+
+	var vms struct {
+		VM []struct {
+			ID     string `json:"id"`
+			Name   string `json:"name"`
+			Status string `json:"status"`
+			Memory string `json:"memory"`
+		} `json:"vm"`
+	}
+	err := c.GetAndParse("/vms", &vms, JSON())
+	if err != nil {
+		t.Fatalf("GetAndParse: %v", err)
+	}
+	if len(vms.VM) != 2 || vms.VM[0].Name != "web01" || vms.VM[1].Status != "down" || vms.VM[1].Memory != "8589934592" {
+		t.Fatalf("unexpected vms %+v", vms)
+	}
+
+	// XML stays the default
+	xmlVMs := &VMs{}
+	err = c.GetAndParse("/vms", xmlVMs)
+	if err != nil || len(xmlVMs.VMs) != 2 {
+		t.Fatalf("expected the XML listing, got %+v (%v)", xmlVMs, err)
+	}
+}
+
+// expectTransport records the Expect header of requests and removes 201-created before passing
+// them on, as the HTTP server of the test engine answers unknown expectations with 417
+type expectTransport struct {
+	mu     sync.Mutex
+	expect []string
+}
+
+func (et *expectTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	et.mu.Lock()
+	et.expect = append(et.expect, req.Header.Get("Expect"))
+	et.mu.Unlock()
+
+	if req.Header.Get("Expect") == "201-created" {
+		req = req.Clone(req.Context())
+		req.Header.Del("Expect")
+		req.Header.Set("X-Expect", "201-created")
+	}
+
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestExpectCreated(t *testing.T) {
+	e := newTestEngine(t)
+	e.handle("/vms", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Expect") != "201-created" {
+			writeXML(w, http.StatusAccepted, `<vm id="123"><status>image_locked</status></vm>`)
+			return
+		}
+		writeXML(w, http.StatusCreated, `<vm id="123"><name>web01</name><status>down</status></vm>`)
+	})
+	et := &expectTransport{}
+	c := e.client(t, WithHTTPClient(&http.Client{Transport: et}), WithExpectContinue(time.Second))
+
+	vm := &VM{}
+	err := c.SendObject("/vms", "POST", &VM{Name: "web01"}, vm, ExpectCreated())
+	if err != nil {
+		t.Fatalf("SendObject: %v", err)
+	}
+
+	et.mu.Lock()
+	expect := et.expect[len(et.expect)-1]
+	et.mu.Unlock()
+	if expect != "201-created" {
+		t.Fatalf("expected Expect: 201-created to replace 100-continue, got %q", expect)
+	}
+	if vm.ID != "123" || vm.Name != "web01" || vm.Status != "down" {
+		t.Fatalf("expected the created entity, got %+v", vm)
+	}
+}
+
+func TestHeader(t *testing.T) {
+	e := newTestEngine(t)
+	hr := &headerRecorder{body: `<vm id="123"/>`}
+	e.handle("/vms/123", hr.ServeHTTP)
+	c := e.client(t)
+
+	_, err := c.SendRequest("/vms/123", "PUT", nil, Header("If-Match", `"etag-1"`), Header("Accept", "application/json"))
+	if err != nil {
+		t.Fatalf("SendRequest: %v", err)
+	}
+
+	h := hr.last()
+	if got := h.Get("If-Match"); got != `"etag-1"` {
+		t.Fatalf("expected If-Match to be sent, got %q", got)
+	}
+	// options are applied after the built-in headers
+	if got := h.Get("Accept"); got != "application/json" {
+		t.Fatalf("expected Accept to be overridden, got %q", got)
+	}
+	if got := h.Get("Authorization"); got != "Bearer token-1" {
+		t.Fatalf("expected the token to be sent, got %q", got)
+	}
+}
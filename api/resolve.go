@@ -0,0 +1,122 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrNotFound is returned if no entity matches a name
+	ErrNotFound = errors.New("not found")
+
+	// ErrMultipleMatches is returned if more than one entity matches a name
+	ErrMultipleMatches = errors.New("multiple matches")
+)
+
+// resolvableTypes are the collections ResolveID supports
+var resolvableTypes = map[string]bool{
+	"vms":            true,
+	"hosts":          true,
+	"clusters":       true,
+	"storagedomains": true,
+	"networks":       true,
+	"templates":      true,
+	"datacenters":    true,
+	"instancetypes":  true,
+}
+
+// namedEntities is a collection of any entity type reduced to id and name
+type namedEntities struct {
+	Items []struct {
+		ID   string `xml:"id,attr"`
+		Name string `xml:"name"`
+	} `xml:",any"`
+}
+
+// resolveCache caches resolved ids for a limited time
+type resolveCache struct {
+	ttl     time.Duration
+	mu      sync.Mutex
+	entries map[string]resolveCacheEntry
+}
+
+type resolveCacheEntry struct {
+	id      string
+	expires time.Time
+}
+
+// WithResolveCache caches the ids found by ResolveID for the given duration
+func WithResolveCache(ttl time.Duration) ClientOption {
+	return func(c *Client) {
+		c.resolveCache = &resolveCache{ttl: ttl, entries: make(map[string]resolveCacheEntry)}
+	}
+}
+
+// ResolveID returns the id of the entity with the given name. resourceType is the name
+// of the collection: vms, hosts, clusters, storagedomains, networks, templates, datacenters or instancetypes.
+// It returns ErrNotFound or ErrMultipleMatches if the name is not unique.
+func (c *Client) ResolveID(resourceType, name string) (string, error) {
+	if !resolvableTypes[resourceType] {
+		return "", fmt.Errorf("resource type %q can not be resolved", resourceType)
+	}
+
+	key := resourceType + "/" + name
+	if id, ok := c.resolveCache.get(key); ok {
+		return id, nil
+	}
+
+	// names containing wildcards could match other entities, the exact comparison below filters them
+	res := &namedEntities{}
+	err := c.GetAndParse(searchPath("/"+resourceType, "name="+quoteSearchValue(name)), res)
+	if err != nil {
+		return "", err
+	}
+
+	ids := []string{}
+	for _, e := range res.Items {
+		if e.Name == name {
+			ids = append(ids, e.ID)
+		}
+	}
+
+	switch len(ids) {
+	case 0:
+		return "", fmt.Errorf("%s %q: %w", strings.TrimSuffix(resourceType, "s"), name, ErrNotFound)
+	case 1:
+		c.resolveCache.put(key, ids[0])
+		return ids[0], nil
+	default:
+		return "", fmt.Errorf("%s %q: %w", strings.TrimSuffix(resourceType, "s"), name, ErrMultipleMatches)
+	}
+}
+
+func (rc *resolveCache) get(key string) (string, bool) {
+	if rc == nil {
+		return "", false
+	}
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	e, ok := rc.entries[key]
+	if !ok || time.Now().After(e.expires) {
+		delete(rc.entries, key)
+		return "", false
+	}
+
+	return e.id, true
+}
+
+func (rc *resolveCache) put(key, id string) {
+	if rc == nil {
+		return
+	}
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	rc.entries[key] = resolveCacheEntry{id: id, expires: time.Now().Add(rc.ttl)}
+}
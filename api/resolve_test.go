@@ -0,0 +1,108 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// handleClusterSearch answers cluster searches with the clusters given as name and id pairs
+// and returns the number of searches
+func (e *testEngine) handleClusterSearch(clusters ...string) *int32 {
+	var n int32
+	e.handle("/clusters", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&n, 1)
+		body := "<clusters>"
+		for i := 0; i+1 < len(clusters); i += 2 {
+			body += `<cluster id="` + clusters[i+1] + `"><name>` + clusters[i] + `</name></cluster>`
+		}
+		writeXML(w, http.StatusOK, body+"</clusters>")
+	})
+
+	return &n
+}
+
+func TestResolveID(t *testing.T) {
+	e := newTestEngine(t)
+	// the search matches prod* as well, only the exact name counts
+	searches := e.handleClusterSearch("prod", "c1", "prod-old", "c2")
+	var query string
+	c := e.client(t, WithRequestLogger(func(info RequestInfo) {
+		query = info.URL
+	}))
+
+	id, err := c.ResolveID("clusters", "prod")
+	if err != nil {
+		t.Fatalf("ResolveID: %v", err)
+	}
+	if id != "c1" {
+		t.Fatalf("expected c1, got %s", id)
+	}
+	if atomic.LoadInt32(searches) != 1 {
+		t.Fatalf("expected a single search, got %d", atomic.LoadInt32(searches))
+	}
+	if want := e.apiURL() + "/clusters?search=name%3Dprod"; query != want {
+		t.Fatalf("expected the search %s, got %s", want, query)
+	}
+
+	_, err = c.ResolveID("nics", "nic1")
+	if err == nil {
+		t.Fatal("expected an error for a collection which can not be resolved")
+	}
+}
+
+func TestResolveIDCache(t *testing.T) {
+	e := newTestEngine(t)
+	searches := e.handleClusterSearch("prod", "c1")
+	c := e.client(t, WithResolveCache(50*time.Millisecond))
+
+	for i := 0; i < 3; i++ {
+		id, err := c.ResolveID("clusters", "prod")
+		if err != nil || id != "c1" {
+			t.Fatalf("ResolveID %d: %s, %v", i+1, id, err)
+		}
+	}
+	if n := atomic.LoadInt32(searches); n != 1 {
+		t.Fatalf("expected the id to be cached within the ttl, got %d searches", n)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+
+	id, err := c.ResolveID("clusters", "prod")
+	if err != nil || id != "c1" {
+		t.Fatalf("ResolveID after expiry: %s, %v", id, err)
+	}
+	if n := atomic.LoadInt32(searches); n != 2 {
+		t.Fatalf("expected the id to be searched again after the ttl, got %d searches", n)
+	}
+}
+
+func TestResolveIDNotUnique(t *testing.T) {
+	e := newTestEngine(t)
+	searches := e.handleClusterSearch("prod", "c1", "prod", "c2", "prod-old", "c3")
+	c := e.client(t, WithResolveCache(time.Minute))
+
+	_, err := c.ResolveID("clusters", "prod")
+	if !errors.Is(err, ErrMultipleMatches) {
+		t.Fatalf("expected ErrMultipleMatches, got %v", err)
+	}
+	if err.Error() != `cluster "prod": multiple matches` {
+		t.Fatalf("unexpected error message %q", err)
+	}
+
+	_, err = c.ResolveID("clusters", "prod-new")
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+
+	// failed lookups are not cached
+	_, err = c.ResolveID("clusters", "prod")
+	if !errors.Is(err, ErrMultipleMatches) {
+		t.Fatalf("expected ErrMultipleMatches again, got %v", err)
+	}
+	if n := atomic.LoadInt32(searches); n != 3 {
+		t.Fatalf("expected 3 searches, got %d", n)
+	}
+}
@@ -0,0 +1,203 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// minMaxRetryDelay is the lowest default of the longest delay before a retry, so short backoffs
+// still honour a Retry-After header of a few seconds
+const minMaxRetryDelay = time.Minute
+
+// retryPolicy configures retries of requests failing with transient errors
+type retryPolicy struct {
+	maxAttempts   int
+	baseDelay     time.Duration
+	maxDelay      time.Duration
+	nonIdempotent bool
+}
+
+// WithRetry retries requests failing with network errors or the status codes 429, 503 and 504
+// up to maxAttempts attempts in total. The delay before the n-th retry is baseDelay * 2^(n-1)
+// with jitter, or the delay requested by a Retry-After header if it is longer, but at most the
+// longest backoff of the policy or one minute, whichever is longer (see WithRetryMaxDelay).
+// Retries stop when the request context is done (ctx.Err() is returned, also while waiting for
+// the next attempt) or its deadline would be exceeded.
+// POST requests are not retried unless WithRetryNonIdempotent is passed too.
+func WithRetry(maxAttempts int, baseDelay time.Duration) ClientOption {
+	return func(c *Client) {
+		if c.retry == nil {
+			c.retry = &retryPolicy{}
+		}
+
+		c.retry.maxAttempts = maxAttempts
+		c.retry.baseDelay = baseDelay
+	}
+}
+
+// WithRetryMaxDelay limits the delay before a retry (including the delay requested by a Retry-After
+// header) to d instead of the longest backoff of the policy
+func WithRetryMaxDelay(d time.Duration) ClientOption {
+	return func(c *Client) {
+		if c.retry == nil {
+			c.retry = &retryPolicy{}
+		}
+
+		c.retry.maxDelay = d
+	}
+}
+
+// WithRetryNonIdempotent allows WithRetry to retry POST requests. Actions or creations may be
+// executed twice if the engine processed a request but the response got lost.
+func WithRetryNonIdempotent() ClientOption {
+	return func(c *Client) {
+		if c.retry == nil {
+			c.retry = &retryPolicy{}
+		}
+
+		c.retry.nonIdempotent = true
+	}
+}
+
+// attempts returns the number of attempts for a request using method
+func (p *retryPolicy) attempts(method string) int {
+	if p == nil || p.maxAttempts < 1 {
+		return 1
+	}
+
+	if !p.nonIdempotent && !isIdempotent(method) {
+		return 1
+	}
+
+	return p.maxAttempts
+}
+
+// delay returns the backoff before retry number n (starting at 1): the exponential delay
+// reduced by a random amount of up to half of it
+func (p *retryPolicy) delay(n int) time.Duration {
+	d := p.baseDelay << uint(n-1)
+	if d <= 0 {
+		return 0
+	}
+
+	half := int64(d / 2)
+	return time.Duration(half + rand.Int63n(half+1))
+}
+
+// clamp limits the delay d before a retry to the configured maximum, or else to the longest
+// backoff of the policy
+func (p *retryPolicy) clamp(d time.Duration) time.Duration {
+	max := p.maxDelay
+	if max <= 0 {
+		max = minMaxRetryDelay
+		if n := p.maxAttempts - 1; n > 0 {
+			if b := p.baseDelay << uint(n-1); b > max {
+				max = b
+			}
+		}
+	}
+
+	if d > max {
+		return max
+	}
+
+	return d
+}
+
+// retryAfter returns the delay requested by the Retry-After header of a 429 or 503 response,
+// given in seconds or as HTTP date. It returns 0 if there is none.
+func retryAfter(err error, now time.Time) time.Duration {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Header == nil {
+		return 0
+	}
+
+	v := apiErr.Header.Get("Retry-After")
+	if v == "" {
+		return 0
+	}
+
+	if s, err := strconv.Atoi(v); err == nil {
+		if s < 0 {
+			return 0
+		}
+		return time.Duration(s) * time.Second
+	}
+
+	t, err := http.ParseTime(v)
+	if err != nil || !t.After(now) {
+		return 0
+	}
+
+	return t.Sub(now)
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case "GET", "HEAD", "OPTIONS", "PUT", "DELETE":
+		return true
+	}
+
+	return false
+}
+
+// isRetryable reports if err is a transient failure worth retrying
+func isRetryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.StatusCode {
+		case 429, 503, 504:
+			return true
+		}
+
+		return false
+	}
+
+	// errors of the HTTP client (connection refused or reset, timeouts); errors returned
+	// before the request is sent (like ErrNoCredentials) are not retried
+	return errors.As(err, new(*url.Error))
+}
+
+// sendRequest sends the request, retrying transient failures according to the retry policy
+func (c *Client) sendRequest(ctx context.Context, path, method string, payload []byte, reauth bool, opts []RequestOption) (*Response, error) {
+	attempts := c.retry.attempts(method)
+	reauth = reauth && !c.noAutoReauth
+
+	for n := 1; ; n++ {
+		kind := FirstAttempt
+		if n > 1 {
+			kind = RetryAttempt
+		}
+
+		res, err := c.sendRequestOnce(ctx, path, method, payload, kind, reauth, opts)
+		if err == nil || n >= attempts || !isRetryable(ctx, err) {
+			return res, err
+		}
+
+		d := c.retry.delay(n)
+		// Retry-After dates and context deadlines refer to the real time, not the clock of the client
+		if ra := retryAfter(err, time.Now()); ra > d {
+			d = ra
+		}
+		d = c.retry.clamp(d)
+		if deadline, ok := ctx.Deadline(); ok && time.Now().Add(d).After(deadline) {
+			return res, err
+		}
+
+		c.logger.Debugf("%s %s failed, retrying in %s (attempt %d of %d): %v", method, path, d, n+1, attempts, err)
+
+		err = c.sleep(ctx, d)
+		if err != nil {
+			return nil, err
+		}
+	}
+}
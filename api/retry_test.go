@@ -0,0 +1,285 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetryBackoffCancelled(t *testing.T) {
+	e := newTestEngine(t)
+	e.handle("/vms", func(w http.ResponseWriter, r *http.Request) {
+		writeXML(w, http.StatusServiceUnavailable, "")
+	})
+	c := e.client(t, WithRetry(3, 10*time.Second))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+	_, err := c.GetContext(ctx, "/vms")
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+
+	if d := time.Since(start); d > time.Second {
+		t.Fatalf("request returned after %s instead of when the context was cancelled", d)
+	}
+}
+
+func TestRetry(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		status   int
+		opts     []ClientOption
+		attempts int32
+		ok       bool
+	}{
+		{"503 then success", "GET", http.StatusServiceUnavailable, nil, 3, true},
+		{"429 then success", "PUT", http.StatusTooManyRequests, nil, 3, true},
+		{"400 is not retried", "GET", http.StatusBadRequest, nil, 1, false},
+		{"POST is not retried", "POST", http.StatusServiceUnavailable, nil, 1, false},
+		{"POST retried if allowed", "POST", http.StatusServiceUnavailable, []ClientOption{WithRetryNonIdempotent()}, 3, true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			e := newTestEngine(t)
+			var attempts int32
+			e.handle("/vms", func(w http.ResponseWriter, r *http.Request) {
+				if atomic.AddInt32(&attempts, 1) <= 2 {
+					writeXML(w, tc.status, "")
+					return
+				}
+
+				writeXML(w, http.StatusOK, "<vms/>")
+			})
+			c := e.client(t, append([]ClientOption{WithRetry(5, time.Millisecond)}, tc.opts...)...)
+
+			_, err := c.SendRequest("/vms", tc.method, nil)
+			if tc.ok && err != nil {
+				t.Fatal(err)
+			}
+
+			if !tc.ok && !isStatus(err, tc.status) {
+				t.Fatalf("expected status %d, got %v", tc.status, err)
+			}
+
+			if got := atomic.LoadInt32(&attempts); got != tc.attempts {
+				t.Fatalf("expected %d attempts, got %d", tc.attempts, got)
+			}
+		})
+	}
+}
+
+func TestRetryMaxAttempts(t *testing.T) {
+	e := newTestEngine(t)
+	var attempts int32
+	e.handle("/vms", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		writeXML(w, http.StatusGatewayTimeout, "")
+	})
+	c := e.client(t, WithRetry(3, time.Millisecond))
+
+	_, err := c.Get("/vms")
+	if !isStatus(err, http.StatusGatewayTimeout) {
+		t.Fatalf("expected status 504, got %v", err)
+	}
+
+	if got := atomic.LoadInt32(&attempts); got != 3 {
+		t.Fatalf("expected 3 attempts, got %d", got)
+	}
+}
+
+func TestRetryNetworkError(t *testing.T) {
+	e := newTestEngine(t)
+	var attempts int32
+	e.handle("/vms", func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			// drop the connection without a response
+			conn, _, _ := w.(http.Hijacker).Hijack()
+			conn.Close()
+			return
+		}
+
+		writeXML(w, http.StatusOK, "<vms/>")
+	})
+	c := e.client(t, WithRetry(2, time.Millisecond))
+
+	_, err := c.Get("/vms")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := atomic.LoadInt32(&attempts); got != 2 {
+		t.Fatalf("expected 2 attempts, got %d", got)
+	}
+}
+
+func TestRetryWithClock(t *testing.T) {
+	e := newTestEngine(t)
+	issued := e.handleExpiringTokens("/expiring/sso/oauth/token", time.Hour)
+	var attempts int32
+	e.handle("/vms", func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1)%2 == 1 {
+			writeXML(w, http.StatusServiceUnavailable, "")
+			return
+		}
+		writeXML(w, http.StatusOK, "<vms/>")
+	})
+
+	// the clock of the client is far ahead of the deadline of the context, which must not stop retries
+	clock := newFakeClock()
+	clock.t = time.Now().Add(24 * time.Hour)
+	c := e.client(t, WithClock(clock.now), WithSSOURL(e.URL+"/expiring/sso/oauth/token"), WithRetry(2, time.Hour))
+
+	var delays []time.Duration
+	c.sleep = func(ctx context.Context, d time.Duration) error {
+		delays = append(delays, d)
+		clock.advance(d)
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Hour)
+	defer cancel()
+
+	_, err := c.GetContext(ctx, "/vms")
+	if err != nil {
+		t.Fatalf("GetContext: %v", err)
+	}
+	if len(delays) != 1 || delays[0] < 30*time.Minute || delays[0] > time.Hour {
+		t.Fatalf("expected a single backoff of 30m to 1h, got %v", delays)
+	}
+
+	// together with the backoff the clock is past the expiry of the token, so it is replaced
+	clock.advance(time.Hour)
+	_, err = c.GetContext(ctx, "/vms")
+	if err != nil {
+		t.Fatalf("GetContext: %v", err)
+	}
+	if n := atomic.LoadInt32(issued); n != 2 {
+		t.Fatalf("expected the token to be refreshed by the clock, got %d tokens", n)
+	}
+	if n := atomic.LoadInt32(&attempts); n != 4 {
+		t.Fatalf("expected 4 attempts, got %d", n)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name     string
+		value    string
+		min, max time.Duration
+	}{
+		{"seconds", "7", 7 * time.Second, 7 * time.Second},
+		{"http date", now.Add(20 * time.Second).UTC().Format(http.TimeFormat), 18 * time.Second, 20 * time.Second},
+		{"date in the past", now.Add(-time.Minute).UTC().Format(http.TimeFormat), 0, 0},
+		{"negative", "-1", 0, 0},
+		{"invalid", "soon", 0, 0},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			e := newTestEngine(t)
+			var attempts int32
+			e.handle("/vms", func(w http.ResponseWriter, r *http.Request) {
+				if atomic.AddInt32(&attempts, 1) == 1 {
+					w.Header().Set("Retry-After", tc.value)
+					writeXML(w, http.StatusServiceUnavailable, "")
+					return
+				}
+				writeXML(w, http.StatusOK, "<vms/>")
+			})
+			c := e.client(t, WithRetry(2, time.Nanosecond))
+
+			var delays []time.Duration
+			c.sleep = func(ctx context.Context, d time.Duration) error {
+				delays = append(delays, d)
+				return nil
+			}
+
+			_, err := c.Get("/vms")
+			if err != nil {
+				t.Fatalf("Get: %v", err)
+			}
+			if len(delays) != 1 || delays[0] < tc.min || delays[0] > tc.max {
+				t.Fatalf("expected a delay between %s and %s, got %v", tc.min, tc.max, delays)
+			}
+		})
+	}
+}
+
+func TestRetryAfterDeadline(t *testing.T) {
+	e := newTestEngine(t)
+	var attempts int32
+	e.handle("/vms", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.Header().Set("Retry-After", "60")
+		writeXML(w, http.StatusServiceUnavailable, "")
+	})
+	c := e.client(t, WithRetry(3, time.Millisecond))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// waiting for a minute would exceed the deadline, so the 503 is returned right away
+	_, err := c.GetContext(ctx, "/vms")
+	if !isStatus(err, http.StatusServiceUnavailable) {
+		t.Fatalf("expected the 503, got %v", err)
+	}
+	if n := atomic.LoadInt32(&attempts); n != 1 {
+		t.Fatalf("expected a single attempt, got %d", n)
+	}
+}
+
+func TestRetryMaxDelay(t *testing.T) {
+	tests := []struct {
+		name       string
+		retryAfter string
+		opts       []ClientOption
+		want       time.Duration
+	}{
+		{"default", "86400", []ClientOption{WithRetry(2, time.Nanosecond)}, time.Minute},
+		{"longest backoff", "86400", []ClientOption{WithRetry(4, time.Minute)}, 4 * time.Minute},
+		{"configured", "86400", []ClientOption{WithRetry(2, time.Nanosecond), WithRetryMaxDelay(5 * time.Second)}, 5 * time.Second},
+		{"below the maximum", "3", []ClientOption{WithRetry(2, time.Nanosecond), WithRetryMaxDelay(5 * time.Second)}, 3 * time.Second},
+		{"backoff", "", []ClientOption{WithRetry(2, 24*time.Hour), WithRetryMaxDelay(2 * time.Second)}, 2 * time.Second},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			e := newTestEngine(t)
+			var attempts int32
+			e.handle("/vms", func(w http.ResponseWriter, r *http.Request) {
+				if atomic.AddInt32(&attempts, 1) == 1 {
+					if tc.retryAfter != "" {
+						w.Header().Set("Retry-After", tc.retryAfter)
+					}
+					writeXML(w, http.StatusServiceUnavailable, "")
+					return
+				}
+				writeXML(w, http.StatusOK, "<vms/>")
+			})
+			c := e.client(t, tc.opts...)
+
+			var delays []time.Duration
+			c.sleep = func(ctx context.Context, d time.Duration) error {
+				delays = append(delays, d)
+				return nil
+			}
+
+			_, err := c.Get("/vms")
+			if err != nil {
+				t.Fatalf("Get: %v", err)
+			}
+			if len(delays) != 1 || delays[0] != tc.want {
+				t.Fatalf("expected a delay of %s, got %v", tc.want, delays)
+			}
+		})
+	}
+}
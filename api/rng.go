@@ -0,0 +1,43 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+)
+
+// RNGSource is the entropy source of the random number generator device of a VM
+type RNGSource string
+
+const (
+	// RNGSourceURandom uses /dev/urandom of the host
+	RNGSourceURandom RNGSource = "urandom"
+	// RNGSourceHWRNG uses the hardware random number generator of the host
+	RNGSourceHWRNG RNGSource = "hwrng"
+)
+
+// RNGDevice is the virtio random number generator device of a VM
+type RNGDevice struct {
+	Source RNGSource `xml:"source,omitempty"`
+	Rate   *RNGRate  `xml:"rate,omitempty"`
+}
+
+// RNGRate limits the entropy the guest may consume to Bytes per Period (in milliseconds)
+type RNGRate struct {
+	Bytes  int `xml:"bytes"`
+	Period int `xml:"period"`
+}
+
+// Validate checks the source is supported and the rate is sane
+func (d *RNGDevice) Validate() error {
+	switch d.Source {
+	case RNGSourceURandom, RNGSourceHWRNG:
+	default:
+		return fmt.Errorf("unsupported rng source %q, expected %s or %s", d.Source, RNGSourceURandom, RNGSourceHWRNG)
+	}
+
+	if d.Rate != nil && (d.Rate.Bytes <= 0 || d.Rate.Period <= 0) {
+		return errors.New("rng rate bytes and period must be greater than 0")
+	}
+
+	return nil
+}
@@ -0,0 +1,121 @@
+package api
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// SearchQuery builds queries in the search language of the engine, e.g.
+//
+//	NewSearchQuery().Equals("name", "web*").And().Equals("status", "down").Sortby("name", true).Max(10)
+type SearchQuery struct {
+	terms  []string
+	sortby string
+	max    int
+}
+
+// NewSearchQuery returns an empty search query
+func NewSearchQuery() *SearchQuery {
+	return &SearchQuery{}
+}
+
+// Equals adds the condition field=value. Values containing white space, quotes or operators are quoted.
+func (q *SearchQuery) Equals(field, value string) *SearchQuery {
+	q.terms = append(q.terms, field+"="+quoteSearchValue(value))
+	return q
+}
+
+// NotEquals adds the condition field!=value
+func (q *SearchQuery) NotEquals(field, value string) *SearchQuery {
+	q.terms = append(q.terms, field+"!="+quoteSearchValue(value))
+	return q
+}
+
+// And combines the previous and the next condition with and
+func (q *SearchQuery) And() *SearchQuery {
+	q.terms = append(q.terms, "and")
+	return q
+}
+
+// Or combines the previous and the next condition with or
+func (q *SearchQuery) Or() *SearchQuery {
+	q.terms = append(q.terms, "or")
+	return q
+}
+
+// Sortby orders the result by field
+func (q *SearchQuery) Sortby(field string, asc bool) *SearchQuery {
+	order := "desc"
+	if asc {
+		order = "asc"
+	}
+
+	q.sortby = "sortby " + field + " " + order
+	return q
+}
+
+// Max limits the number of returned items
+func (q *SearchQuery) Max(n int) *SearchQuery {
+	q.max = n
+	return q
+}
+
+// String returns the unescaped search query
+func (q *SearchQuery) String() string {
+	terms := q.terms
+	if q.sortby != "" {
+		terms = append(terms[:len(terms):len(terms)], q.sortby)
+	}
+
+	return strings.Join(terms, " ")
+}
+
+// Encode returns the URL encoded query parameters (search and max)
+func (q *SearchQuery) Encode() string {
+	params := []string{}
+	if s := q.String(); s != "" {
+		params = append(params, "search="+escapeSearch(s))
+	}
+
+	if q.max > 0 {
+		params = append(params, "max="+strconv.Itoa(q.max))
+	}
+
+	return strings.Join(params, "&")
+}
+
+// Path appends the encoded query to the collection path, e.g. for use with Get
+func (q *SearchQuery) Path(collection string) string {
+	p := q.Encode()
+	if p == "" {
+		return collection
+	}
+
+	return collection + "?" + p
+}
+
+// searchPath appends the search query to the collection path
+func searchPath(collection, query string) string {
+	return collection + "?search=" + escapeSearch(query)
+}
+
+// escapeSearch escapes a search query for use in the URL. The engine does not decode
+// "+" as space reliably, so spaces are sent as "%20".
+func escapeSearch(query string) string {
+	return strings.Replace(url.QueryEscape(query), "+", "%20", -1)
+}
+
+// searchSpecialChars are the characters which split a value into several terms or operators
+// (white space, quotes and the comparison operators) or change its meaning (parentheses)
+const searchSpecialChars = " \t\n\"'=!<>:()"
+
+// quoteSearchValue quotes values containing special characters (and empty values) so the engine
+// treats them as one term. Quotes inside the value are escaped.
+func quoteSearchValue(v string) string {
+	if v == "" || strings.ContainsAny(v, searchSpecialChars) {
+		return `"` + strings.Replace(v, `"`, `\"`, -1) + `"`
+	}
+
+	return v
+}
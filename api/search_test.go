@@ -0,0 +1,83 @@
+package api
+
+import "testing"
+
+func TestSearchQuery(t *testing.T) {
+	tests := []struct {
+		q    *SearchQuery
+		want string
+	}{
+		{
+			NewSearchQuery().Equals("name", "web*").And().Equals("status", "down"),
+			"search=name%3Dweb%2A%20and%20status%3Ddown",
+		},
+		{
+			NewSearchQuery().Equals("name", "my vm").Sortby("name", true).Max(10),
+			"search=name%3D%22my%20vm%22%20sortby%20name%20asc&max=10",
+		},
+		{
+			NewSearchQuery().Equals("description", "a+b&c").Or().NotEquals("cluster", "x/y"),
+			"search=description%3Da%2Bb%26c%20or%20cluster%21%3Dx%2Fy",
+		},
+		{
+			// an unquoted a"or would start a quoted term swallowing the rest of the query
+			NewSearchQuery().Equals("name", `a"or`).Or().Equals("name", "b"),
+			"search=name%3D%22a%5C%22or%22%20or%20name%3Db",
+		},
+		{
+			NewSearchQuery().Equals("description", "x=1").And().NotEquals("comment", "a<b"),
+			"search=description%3D%22x%3D1%22%20and%20comment%21%3D%22a%3Cb%22",
+		},
+		{
+			NewSearchQuery().Equals("description", ""),
+			"search=description%3D%22%22",
+		},
+		{
+			NewSearchQuery().Sortby("creation_date", false),
+			"search=sortby%20creation_date%20desc",
+		},
+		{
+			NewSearchQuery().Max(5),
+			"max=5",
+		},
+	}
+
+	for _, tc := range tests {
+		if got := tc.q.Encode(); got != tc.want {
+			t.Errorf("%s: expected %s, got %s", tc.q, tc.want, got)
+		}
+	}
+
+	if got := NewSearchQuery().Equals("name", "web").Path("/vms"); got != "/vms?search=name%3Dweb" {
+		t.Errorf("unexpected path %s", got)
+	}
+
+	if got := NewSearchQuery().Path("/vms"); got != "/vms" {
+		t.Errorf("unexpected path %s for an empty query", got)
+	}
+}
+
+func TestQuoteSearchValue(t *testing.T) {
+	tests := map[string]string{
+		"web01":     "web01",
+		"web*":      "web*",
+		"a+b&c/d":   "a+b&c/d",
+		"my vm":     `"my vm"`,
+		`a"or`:      `"a\"or"`,
+		`say "hi"`:  `"say \"hi\""`,
+		"it's":      `"it's"`,
+		"x=1":       `"x=1"`,
+		"a!=b":      `"a!=b"`,
+		"<1>":       `"<1>"`,
+		"Vms:":      `"Vms:"`,
+		"(a)":       `"(a)"`,
+		"":          `""`,
+		"tab\there": "\"tab\there\"",
+	}
+
+	for v, want := range tests {
+		if got := quoteSearchValue(v); got != want {
+			t.Errorf("%q: expected %s, got %s", v, want, got)
+		}
+	}
+}
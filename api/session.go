@@ -0,0 +1,47 @@
+package api
+
+import "encoding/xml"
+
+// Sessions is a collection of VM sessions as returned by the API
+type Sessions struct {
+	Sessions []Session `xml:"session"`
+}
+
+// Session is a console or guest login session of a VM
+type Session struct {
+	XMLName     xml.Name     `xml:"session"`
+	ID          string       `xml:"id,attr,omitempty"`
+	ConsoleUser bool         `xml:"console_user"`
+	Protocol    string       `xml:"protocol,omitempty"`
+	IP          *IP          `xml:"ip,omitempty"`
+	User        *SessionUser `xml:"user,omitempty"`
+}
+
+// IP is an IP address
+type IP struct {
+	Address string `xml:"address,omitempty"`
+	Version string `xml:"version,omitempty"`
+}
+
+// SessionUser is the user owning a session
+type SessionUser struct {
+	ID       string `xml:"id,attr,omitempty"`
+	Href     string `xml:"href,attr,omitempty"`
+	UserName string `xml:"user_name,omitempty"`
+}
+
+// ListVMSessions retrieves the active console and guest sessions of a VM.
+// It returns an empty slice if nobody is connected.
+func (c *Client) ListVMSessions(vmID string) ([]Session, error) {
+	res := &Sessions{}
+	err := c.GetAndParse("/vms/"+vmID+"/sessions", res)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.Sessions == nil {
+		return []Session{}, nil
+	}
+
+	return res.Sessions, nil
+}
@@ -0,0 +1,49 @@
+package api
+
+import (
+	"context"
+	"encoding/xml"
+	"time"
+)
+
+// StorageDomains is a collection of storage domains as returned by the API
+type StorageDomains struct {
+	StorageDomains []StorageDomain `xml:"storage_domain"`
+}
+
+// StorageDomain represents a storage domain
+type StorageDomain struct {
+	XMLName    xml.Name `xml:"storage_domain"`
+	ID         string   `xml:"id,attr,omitempty"`
+	Href       string   `xml:"href,attr,omitempty"`
+	Name       string   `xml:"name,omitempty"`
+	Type       string   `xml:"type,omitempty"`
+	Status     string   `xml:"status,omitempty"`
+	Available  int64    `xml:"available,omitempty"`
+	Used       int64    `xml:"used,omitempty"`
+	Committed  int64    `xml:"committed,omitempty"`
+	Master     bool     `xml:"master,omitempty"`
+	DataCenter *Link    `xml:"data_center,omitempty"`
+}
+
+// WaitForStorageDomainStatus polls the storage domain attached to a data center until
+// it reaches the target status (e.g. active). It returns the last status seen, which
+// on timeout tells in which state the domain got stuck.
+func (c *Client) WaitForStorageDomainStatus(dcID, sdID, target string, timeout time.Duration) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	return c.WaitForStorageDomainStatusContext(ctx, dcID, sdID, target)
+}
+
+// WaitForStorageDomainStatusContext polls the storage domain attached to a data center until
+// it reaches the target status or ctx is done. It returns the last status seen.
+func (c *Client) WaitForStorageDomainStatusContext(ctx context.Context, dcID, sdID, target string) (string, error) {
+	path := "/datacenters/" + dcID + "/storagedomains/" + sdID
+
+	return waitForStatus(ctx, "storage domain "+sdID, target, func(ctx context.Context) (string, error) {
+		sd := &StorageDomain{}
+		err := c.GetAndParseContext(ctx, path, sd)
+		return sd.Status, err
+	})
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sync/atomic"
+)
+
+// Stream retrieves path and passes the response body to handler as it is received, so large
+// collections (e.g. events) can be processed element by element with DecodeElement instead of
+// reading the whole document into memory. The body is closed when handler returns.
+func (c *Client) Stream(path string, handler func(decoder *xml.Decoder) error, opts ...RequestOption) error {
+	return c.StreamContext(context.Background(), path, handler, opts...)
+}
+
+// StreamContext retrieves path and passes the response body to handler as it is received
+func (c *Client) StreamContext(ctx context.Context, path string, handler func(decoder *xml.Decoder) error, opts ...RequestOption) error {
+	reauth := !c.noAutoReauth
+	reauthenticated := false
+	for {
+		kind := FirstAttempt
+		if reauthenticated {
+			kind = ReauthAttempt
+		}
+
+		ex, err := c.doRequest(ctx, path, "GET", nil, kind, opts)
+		if err != nil {
+			return err
+		}
+		resp := ex.resp
+
+		if resp.StatusCode >= 300 {
+			b, err := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			c.requestDone(ex.req, kind, resp, 0, len(b), ex.start, err)
+			if err != nil {
+				return err
+			}
+
+			if resp.StatusCode == 401 && reauth {
+				reauth = false
+				retry, err := c.handleRejected(ctx, ex)
+				if err != nil {
+					return err
+				}
+
+				if retry {
+					reauthenticated = true
+					continue
+				}
+			}
+
+			if resp.StatusCode == 401 && reauthenticated {
+				return fmt.Errorf("%w: %w", ErrReauthFailed, ex.apiError(b))
+			}
+
+			return ex.apiError(b)
+		}
+
+		r := &countingReader{r: resp.Body}
+		err = handler(xml.NewDecoder(r))
+		resp.Body.Close()
+		c.requestDone(ex.req, kind, resp, 0, int(r.count()), ex.start, nil)
+
+		return err
+	}
+}
+
+// countingReader counts the bytes read from r. The count is updated atomically, as the HTTP
+// transport may still read a request body after the request failed.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	atomic.AddInt64(&r.n, int64(n))
+	return n, err
+}
+
+// count returns the number of bytes read so far
+func (r *countingReader) count() int64 {
+	return atomic.LoadInt64(&r.n)
+}
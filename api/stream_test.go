@@ -0,0 +1,82 @@
+package api
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestStream(t *testing.T) {
+	const n = 20000
+
+	// the second half of the collection is only sent once the handler decoded the first half,
+	// so the test fails if the body is read completely before the handler is called
+	half := make(chan struct{})
+	e := newTestEngine(t)
+	e.handle("/events", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/xml")
+		fmt.Fprint(w, "<events>")
+		for i := 0; i < n; i++ {
+			if i == n/2 {
+				w.(http.Flusher).Flush()
+				select {
+				case <-half:
+				case <-time.After(5 * time.Second):
+					t.Error("the first half of the events was not streamed to the handler")
+					return
+				}
+			}
+
+			fmt.Fprintf(w, `<event id="%d"><description>event number %d</description><severity>normal</severity></event>`, i, i)
+		}
+		fmt.Fprint(w, "</events>")
+	})
+	c := e.client(t)
+
+	events, tokens := 0, 0
+	err := c.Stream("/events", func(d *xml.Decoder) error {
+		for {
+			tok, err := d.Token()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			tokens++
+
+			se, ok := tok.(xml.StartElement)
+			if !ok || se.Name.Local != "event" {
+				continue
+			}
+
+			ev := &Event{}
+			err = d.DecodeElement(ev, &se)
+			if err != nil {
+				return err
+			}
+			if ev.ID != fmt.Sprint(events) {
+				return fmt.Errorf("expected event %d, got %s", events, ev.ID)
+			}
+
+			events++
+			if events == n/2 {
+				close(half)
+			}
+		}
+	})
+	if err != nil {
+		t.Fatalf("Stream: %v", err)
+	}
+
+	if events != n {
+		t.Fatalf("expected %d events, got %d", n, events)
+	}
+	// <events>, the start elements of the events and </events>
+	if tokens != n+2 {
+		t.Fatalf("expected %d tokens, got %d", n+2, tokens)
+	}
+}
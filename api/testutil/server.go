@@ -0,0 +1,53 @@
+// Package testutil helps testing code using the API client without a running engine
+package testutil
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/msdnna/ovirt_api/api"
+)
+
+// APIPath is the path the API is served at by the test server
+const APIPath = "/ovirt-engine/api"
+
+// Token is the token the client of the test server sends
+const Token = "test-token"
+
+// NewTestServer starts an HTTP server answering requests with the handlers of routes and returns a
+// client for it, which uses a fixed token instead of authenticating. The keys of routes are patterns
+// of http.ServeMux relative to the API (e.g. "/vms" or "/vms/"). Requests without a matching route
+// are answered with 404 and a fault. The returned function stops the server.
+//
+//	c, done := testutil.NewTestServer(map[string]http.HandlerFunc{
+//		"/vms": func(w http.ResponseWriter, r *http.Request) {
+//			w.Write([]byte(`<vms><vm id="123"><name>test</name></vm></vms>`))
+//		},
+//	})
+//	defer done()
+//
+//	vms := &api.VMs{}
+//	err := c.GetAndParse("/vms", vms)
+func NewTestServer(routes map[string]http.HandlerFunc, opts ...api.ClientOption) (*api.Client, func()) {
+	mux := http.NewServeMux()
+	for pattern, h := range routes {
+		mux.HandleFunc(APIPath+"/"+strings.TrimLeft(pattern, "/"), h)
+	}
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/xml")
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte("<fault><reason>Not Found</reason><detail>no route for " + r.URL.Path + "</detail></fault>"))
+	})
+
+	s := httptest.NewServer(mux)
+
+	opts = append([]api.ClientOption{api.WithToken(Token)}, opts...)
+	c, err := api.NewClient(s.URL+APIPath, "", "", opts...)
+	if err != nil {
+		s.Close()
+		panic("testutil: could not create client: " + err.Error())
+	}
+
+	return c, s.Close
+}
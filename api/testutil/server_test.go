@@ -0,0 +1,58 @@
+package testutil_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/msdnna/ovirt_api/api"
+	"github.com/msdnna/ovirt_api/api/testutil"
+)
+
+func ExampleNewTestServer() {
+	c, done := testutil.NewTestServer(map[string]http.HandlerFunc{
+		"/vms": func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`<vms><vm id="123"><name>test</name></vm><vm id="456"><name>db</name></vm></vms>`))
+		},
+	})
+	defer done()
+
+	vms := &api.VMs{}
+	err := c.GetAndParse("/vms", vms)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	for _, vm := range vms.VMs {
+		fmt.Println(vm.ID, vm.Name)
+	}
+	// Output:
+	// 123 test
+	// 456 db
+}
+
+func TestNewTestServer(t *testing.T) {
+	var auth string
+	c, done := testutil.NewTestServer(map[string]http.HandlerFunc{
+		"vms/": func(w http.ResponseWriter, r *http.Request) {
+			auth = r.Header.Get("Authorization")
+			w.Write([]byte(`<vm id="123"/>`))
+		},
+	})
+	defer done()
+
+	vm := &api.VM{}
+	err := c.GetAndParse("/vms/123", vm)
+	if err != nil || vm.ID != "123" {
+		t.Fatalf("GetAndParse: %+v, %v", vm, err)
+	}
+	if auth != "Bearer "+testutil.Token {
+		t.Fatalf("expected the fixed token, got %q", auth)
+	}
+
+	_, err = c.Get("/hosts")
+	if !api.IsNotFound(err) {
+		t.Fatalf("expected 404 for a path without route, got %v", err)
+	}
+}
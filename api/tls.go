@@ -0,0 +1,114 @@
+package api
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// WithClientCert presents the client certificate for mutual TLS (e.g. to a reverse proxy in front
+// of the engine). certPEM and keyPEM are the PEM encoded certificate (chain) and private key.
+func WithClientCert(certPEM, keyPEM []byte) ClientOption {
+	return func(c *Client) {
+		cert, err := tls.X509KeyPair(certPEM, keyPEM)
+		if err != nil {
+			c.setOptionErr(fmt.Errorf("could not load client certificate: %w", err))
+			return
+		}
+
+		c.addClientCert(cert)
+	}
+}
+
+// WithClientCertFile presents the client certificate read from the PEM files certFile and keyFile
+func WithClientCertFile(certFile, keyFile string) ClientOption {
+	return func(c *Client) {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			c.setOptionErr(fmt.Errorf("could not load client certificate: %w", err))
+			return
+		}
+
+		c.addClientCert(cert)
+	}
+}
+
+// WithCACert trusts the PEM encoded CA certificate(s) in addition to the CAs already trusted by the
+// transport, so an engine with a certificate signed by its own CA can be used without WithInsecure.
+// If the transport (e.g. of a client passed with WithHTTPClient) has no root CAs configured,
+// only the given CAs are trusted.
+func WithCACert(pem []byte) ClientOption {
+	return func(c *Client) {
+		c.addCACert(pem)
+	}
+}
+
+// WithCACertFile trusts the PEM encoded CA certificate(s) read from path (see WithCACert),
+// e.g. the CA certificate downloaded from the engine
+func WithCACertFile(path string) ClientOption {
+	return func(c *Client) {
+		pem, err := os.ReadFile(path)
+		if err != nil {
+			c.setOptionErr(fmt.Errorf("could not read CA certificate: %w", err))
+			return
+		}
+
+		c.addCACert(pem)
+	}
+}
+
+func (c *Client) addCACert(pem []byte) {
+	cfg := c.tlsConfig()
+	if cfg == nil {
+		c.setOptionErr(fmt.Errorf("CA certificate can not be set on transport %T", c.client.Transport))
+		return
+	}
+
+	pool := x509.NewCertPool()
+	if cfg.RootCAs != nil {
+		pool = cfg.RootCAs.Clone()
+	}
+
+	if !pool.AppendCertsFromPEM(pem) {
+		c.setOptionErr(errors.New("no valid CA certificate found in PEM data"))
+		return
+	}
+
+	cfg.RootCAs = pool
+}
+
+func (c *Client) addClientCert(cert tls.Certificate) {
+	cfg := c.tlsConfig()
+	if cfg == nil {
+		c.setOptionErr(fmt.Errorf("client certificate can not be set on transport %T", c.client.Transport))
+		return
+	}
+
+	// the slice may be shared with the transport the config was copied from
+	n := len(cfg.Certificates)
+	cfg.Certificates = append(cfg.Certificates[:n:n], cert)
+}
+
+// tlsConfig returns the TLS config of the transport, creating it if necessary.
+// It returns nil if the transport can not be configured.
+func (c *Client) tlsConfig() *tls.Config {
+	tr := c.transport()
+	if tr == nil {
+		return nil
+	}
+
+	if tr.TLSClientConfig == nil {
+		tr.TLSClientConfig = &tls.Config{}
+	}
+
+	return tr.TLSClientConfig
+}
+
+// setOptionErr records the first error of an option
+func (c *Client) setOptionErr(err error) {
+	if c.optionErr == nil {
+		c.optionErr = err
+	}
+}
@@ -0,0 +1,202 @@
+package api
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// testCA issues certificates for the tests
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &testCA{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue returns the PEM encoded certificate and key for a server at 127.0.0.1 or a client
+func (ca *testCA) issue(t *testing.T, server bool) ([]byte, []byte) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: "client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	if server {
+		tmpl.Subject.CommonName = "127.0.0.1"
+		tmpl.IPAddresses = []net.IP{net.ParseIP("127.0.0.1")}
+		tmpl.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+// serverConfig returns the TLS config of a server using a certificate issued by ca
+func (ca *testCA) serverConfig(t *testing.T) *tls.Config {
+	t.Helper()
+
+	cert, err := tls.X509KeyPair(ca.issue(t, true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &tls.Config{Certificates: []tls.Certificate{cert}}
+}
+
+func writeTempFile(t *testing.T, name string, b []byte) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	err := os.WriteFile(path, b, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestWithCACert(t *testing.T) {
+	ca := newTestCA(t)
+	e := newTLSTestEngine(t, ca.serverConfig(t))
+	e.handle("/vms", func(w http.ResponseWriter, r *http.Request) {
+		writeXML(w, http.StatusOK, "<vms/>")
+	})
+
+	options := map[string]ClientOption{
+		"pem":  WithCACert(ca.pem),
+		"file": WithCACertFile(writeTempFile(t, "ca.pem", ca.pem)),
+	}
+
+	for name, opt := range options {
+		t.Run(name, func(t *testing.T) {
+			c := e.client(t, opt)
+
+			_, err := c.Get("/vms")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if c.client.Transport.(*http.Transport).TLSClientConfig.InsecureSkipVerify {
+				t.Fatal("certificate validation was disabled")
+			}
+		})
+	}
+
+	_, err := NewClient(e.apiURL(), "user", "secret")
+	var unknownCA x509.UnknownAuthorityError
+	if !errors.As(err, &unknownCA) {
+		t.Fatalf("expected the certificate to be rejected without the CA, got %v", err)
+	}
+
+	_, err = NewClient(e.apiURL(), "user", "secret", WithCACert([]byte("no certificate")))
+	if err == nil {
+		t.Fatal("expected an error for invalid PEM data")
+	}
+}
+
+func TestWithClientCert(t *testing.T) {
+	ca := newTestCA(t)
+	cfg := ca.serverConfig(t)
+	cfg.ClientAuth = tls.RequireAndVerifyClientCert
+	cfg.ClientCAs = x509.NewCertPool()
+	cfg.ClientCAs.AddCert(ca.cert)
+
+	e := newTLSTestEngine(t, cfg)
+	e.handle("/vms", func(w http.ResponseWriter, r *http.Request) {
+		if len(r.TLS.PeerCertificates) == 0 || r.TLS.PeerCertificates[0].Subject.CommonName != "client" {
+			t.Error("request without client certificate")
+		}
+		writeXML(w, http.StatusOK, "<vms/>")
+	})
+
+	certPEM, keyPEM := ca.issue(t, false)
+	options := map[string][]ClientOption{
+		"pem":           {WithClientCert(certPEM, keyPEM), WithCACert(ca.pem)},
+		"file":          {WithClientCertFile(writeTempFile(t, "cert.pem", certPEM), writeTempFile(t, "key.pem", keyPEM)), WithCACert(ca.pem)},
+		"insecure":      {WithInsecure(), WithClientCert(certPEM, keyPEM)},
+		"ca cert first": {WithCACert(ca.pem), WithClientCert(certPEM, keyPEM)},
+	}
+
+	for name, opts := range options {
+		t.Run(name, func(t *testing.T) {
+			c := e.client(t, opts...)
+
+			_, err := c.Get("/vms")
+			if err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+
+	_, err := NewClient(e.apiURL(), "user", "secret", WithCACert(ca.pem))
+	if err == nil {
+		t.Fatal("engine accepted a connection without client certificate")
+	}
+
+	_, err = NewClient(e.apiURL(), "user", "secret", WithClientCert(certPEM, []byte("no key")))
+	if err == nil {
+		t.Fatal("expected an error for an invalid key")
+	}
+}
@@ -0,0 +1,86 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// WithExpectContinue sends "Expect: 100-continue" with request bodies, so the engine can
+// reject a request (e.g. an upload) before the body is transferred. timeout is how long to
+// wait for the continue response before sending the body anyway.
+func WithExpectContinue(timeout time.Duration) ClientOption {
+	return func(c *Client) {
+		c.expectContinue = timeout
+	}
+}
+
+// WithTimeout limits the duration of each request (including reading the response).
+// It is applied after all other options, so it also applies to a client passed with WithHTTPClient.
+func WithTimeout(d time.Duration) ClientOption {
+	return func(c *Client) {
+		c.timeout = d
+	}
+}
+
+// WithTransportConfig tunes the connection pool of the transport: the maximum number of idle
+// connections in total and per host, the maximum number of connections per host (0 for no limit)
+// and how long idle connections are kept open. It can be combined with the TLS options.
+func WithTransportConfig(maxIdle, maxIdlePerHost, maxConnsPerHost int, idleTimeout time.Duration) ClientOption {
+	return func(c *Client) {
+		tr := c.transport()
+		if tr == nil {
+			c.setOptionErr(fmt.Errorf("connection pool can not be configured on transport %T", c.client.Transport))
+			return
+		}
+
+		tr.MaxIdleConns = maxIdle
+		tr.MaxIdleConnsPerHost = maxIdlePerHost
+		tr.MaxConnsPerHost = maxConnsPerHost
+		tr.IdleConnTimeout = idleTimeout
+	}
+}
+
+// transport returns the transport of the HTTP client, replacing the default transport or a
+// transport shared with another client by a copy so it can be modified safely. It returns nil if
+// a round tripper other than *http.Transport is used, which can not be configured by the client options.
+func (c *Client) transport() *http.Transport {
+	if c.client.Transport == nil {
+		c.client.Transport = http.DefaultTransport.(*http.Transport).Clone()
+		c.sharedTransport = false
+	}
+
+	tr, ok := c.client.Transport.(*http.Transport)
+	if !ok {
+		return nil
+	}
+
+	if c.sharedTransport {
+		tr = tr.Clone()
+		c.client.Transport = tr
+		c.sharedTransport = false
+	}
+
+	return tr
+}
+
+// configureTransport applies transport settings after all options have been evaluated
+func (c *Client) configureTransport() {
+	if c.timeout > 0 {
+		c.client.Timeout = c.timeout
+	}
+
+	if c.client.Transport == nil {
+		// a pool of its own, so Close does not close the connections of http.DefaultTransport
+		c.transport()
+	}
+
+	if c.expectContinue <= 0 {
+		return
+	}
+
+	tr := c.transport()
+	if tr != nil {
+		tr.ExpectContinueTimeout = c.expectContinue
+	}
+}
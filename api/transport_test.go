@@ -0,0 +1,104 @@
+package api
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithTimeout(t *testing.T) {
+	e := newTestEngine(t)
+	e.handle("/vms", func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(300 * time.Millisecond)
+		writeXML(w, http.StatusOK, "<vms/>")
+	})
+
+	options := map[string][]ClientOption{
+		"default client":     {WithTimeout(50 * time.Millisecond)},
+		"with insecure":      {WithTimeout(50 * time.Millisecond), WithInsecure()},
+		"http client after":  {WithTimeout(50 * time.Millisecond), WithHTTPClient(&http.Client{})},
+		"http client before": {WithHTTPClient(&http.Client{}), WithTimeout(50 * time.Millisecond)},
+	}
+
+	for name, opts := range options {
+		t.Run(name, func(t *testing.T) {
+			c := e.client(t, opts...)
+
+			start := time.Now()
+			_, err := c.Get("/vms")
+
+			var netErr net.Error
+			if !errors.As(err, &netErr) || !netErr.Timeout() {
+				t.Fatalf("expected a timeout error, got %v", err)
+			}
+
+			if d := time.Since(start); d >= 300*time.Millisecond {
+				t.Fatalf("request returned after %s", d)
+			}
+		})
+	}
+}
+
+func TestWithTransportConfig(t *testing.T) {
+	ca := newTestCA(t)
+	pool := WithTransportConfig(50, 10, 20, 30*time.Second)
+
+	options := map[string][]ClientOption{
+		"default client":     {pool},
+		"insecure before":    {WithInsecure(), pool},
+		"insecure after":     {pool, WithInsecure()},
+		"ca before":          {WithCACert(ca.pem), pool},
+		"ca after":           {pool, WithCACert(ca.pem)},
+		"with http client":   {WithHTTPClient(&http.Client{Transport: &http.Transport{}}), pool},
+		"with timeout":       {WithTimeout(time.Second), pool},
+		"with client before": {WithHTTPClient(&http.Client{}), pool, WithInsecure()},
+	}
+
+	for name, opts := range options {
+		t.Run(name, func(t *testing.T) {
+			c, err := NewClient("https://engine/ovirt-engine/api", "user", "secret", append(opts, WithLazyAuth())...)
+			if err != nil {
+				t.Fatalf("NewClient: %v", err)
+			}
+
+			tr, ok := c.client.Transport.(*http.Transport)
+			if !ok {
+				t.Fatalf("expected a *http.Transport, got %T", c.client.Transport)
+			}
+			if tr.MaxIdleConns != 50 || tr.MaxIdleConnsPerHost != 10 || tr.MaxConnsPerHost != 20 || tr.IdleConnTimeout != 30*time.Second {
+				t.Fatalf("unexpected pool settings %d, %d, %d, %s", tr.MaxIdleConns, tr.MaxIdleConnsPerHost, tr.MaxConnsPerHost, tr.IdleConnTimeout)
+			}
+
+			if tr == http.DefaultTransport {
+				t.Fatal("the default transport must not be modified")
+			}
+
+			insecure := strings.HasPrefix(name, "insecure") || name == "with client before"
+			if insecure && (tr.TLSClientConfig == nil || !tr.TLSClientConfig.InsecureSkipVerify) {
+				t.Fatal("expected the TLS settings to be kept")
+			}
+			if strings.HasPrefix(name, "ca") && (tr.TLSClientConfig == nil || tr.TLSClientConfig.RootCAs == nil) {
+				t.Fatal("expected the CA to be kept")
+			}
+		})
+	}
+
+	_, err := NewClient("https://engine/ovirt-engine/api", "user", "secret", WithLazyAuth(),
+		WithHTTPClient(&http.Client{Transport: &recordingTransport{}}), pool)
+	if err == nil {
+		t.Fatal("expected an error for a transport which can not be configured")
+	}
+
+	// the configured transport still verifies the engine with the CA
+	e := newTLSTestEngine(t, ca.serverConfig(t))
+	e.handle("/vms", func(w http.ResponseWriter, r *http.Request) {
+		writeXML(w, http.StatusOK, "<vms/>")
+	})
+	_, err = e.client(t, pool, WithCACert(ca.pem)).Get("/vms")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+}
@@ -0,0 +1,37 @@
+package api
+
+// Link references another entity by id or name
+type Link struct {
+	ID   string `xml:"id,attr,omitempty"`
+	Href string `xml:"href,attr,omitempty"`
+	Name string `xml:"name,omitempty"`
+}
+
+// CPU describes the virtual CPU of a VM or instance type
+type CPU struct {
+	Topology *CPUTopology `xml:"topology,omitempty"`
+	CPUTune  *CPUTune     `xml:"cpu_tune,omitempty"`
+}
+
+// CPUTune pins virtual CPUs to physical CPUs of the host
+type CPUTune struct {
+	VcpuPins []VcpuPin `xml:"vcpu_pins>vcpu_pin"`
+}
+
+// VcpuPin pins a virtual CPU to a set of host CPUs (e.g. "0-3,^2")
+type VcpuPin struct {
+	Vcpu   int    `xml:"vcpu"`
+	CPUSet string `xml:"cpu_set"`
+}
+
+// CPUTopology describes the number of sockets, cores and threads of a CPU
+type CPUTopology struct {
+	Sockets int `xml:"sockets,omitempty"`
+	Cores   int `xml:"cores,omitempty"`
+	Threads int `xml:"threads,omitempty"`
+}
+
+// VCPUs returns the number of virtual CPUs of the topology
+func (t *CPUTopology) VCPUs() int {
+	return t.Sockets * t.Cores * t.Threads
+}
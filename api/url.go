@@ -0,0 +1,61 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// normalizeBaseURL validates the API URL passed to NewClient and removes trailing slashes,
+// so https://engine/ovirt-engine/api and https://engine/ovirt-engine/api/ behave the same
+func normalizeBaseURL(raw string) (string, error) {
+	if !strings.Contains(raw, "://") {
+		return "", errors.New("invalid base URL: missing scheme")
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", fmt.Errorf("invalid base URL: %w", err)
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", fmt.Errorf("invalid base URL: unsupported scheme %q", u.Scheme)
+	}
+
+	if u.Host == "" {
+		return "", errors.New("invalid base URL: missing host")
+	}
+
+	if u.RawQuery != "" || u.Fragment != "" {
+		return "", errors.New("invalid base URL: must not contain a query or fragment")
+	}
+
+	u.Path = strings.TrimRight(u.Path, "/")
+	u.RawPath = ""
+	return u.String(), nil
+}
+
+// requestURL resolves the path of a request against the base URL. Paths are relative to the API
+// (vms/123 and /vms/123 are the same), unless they start with the path of the base URL like the
+// hrefs returned by the engine (/ovirt-engine/api/vms/123). Absolute URLs are used as they are.
+func (c *Client) requestURL(path string) (string, error) {
+	base, err := url.Parse(c.url + "/")
+	if err != nil {
+		return "", err
+	}
+
+	ref, err := url.Parse(path)
+	if err != nil {
+		return "", fmt.Errorf("invalid request path %q: %w", path, err)
+	}
+
+	// the root of the API (/ovirt-engine/api) is engine-absolute as well
+	engineAbsolute := ref.Path == strings.TrimSuffix(base.Path, "/") || strings.HasPrefix(ref.Path, base.Path)
+	if !ref.IsAbs() && ref.Host == "" && !engineAbsolute {
+		ref.Path = strings.TrimLeft(ref.Path, "/")
+		ref.RawPath = strings.TrimLeft(ref.RawPath, "/")
+	}
+
+	return base.ResolveReference(ref).String(), nil
+}
@@ -0,0 +1,100 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeBaseURL(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want string
+		err  string
+	}{
+		{raw: "https://engine/ovirt-engine/api", want: "https://engine/ovirt-engine/api"},
+		{raw: "https://engine/ovirt-engine/api/", want: "https://engine/ovirt-engine/api"},
+		{raw: "https://engine/ovirt-engine/api//", want: "https://engine/ovirt-engine/api"},
+		{raw: "http://engine:8080/ovirt-engine/api", want: "http://engine:8080/ovirt-engine/api"},
+		{raw: "https://engine", want: "https://engine"},
+		{raw: "https://[::1]:8443/api", want: "https://[::1]:8443/api"},
+		{raw: "engine.example.com/api", err: "invalid base URL: missing scheme"},
+		{raw: "", err: "invalid base URL: missing scheme"},
+		{raw: "ftp://engine/api", err: `invalid base URL: unsupported scheme "ftp"`},
+		{raw: "https:///ovirt-engine/api", err: "invalid base URL: missing host"},
+		{raw: "https://engine/api?x=1", err: "invalid base URL: must not contain a query or fragment"},
+		{raw: "https://engine/api#top", err: "invalid base URL: must not contain a query or fragment"},
+		{raw: "https://engine:port/api", err: "invalid base URL: "},
+	}
+
+	for _, tc := range tests {
+		got, err := normalizeBaseURL(tc.raw)
+		if tc.err != "" {
+			if err == nil || !strings.HasPrefix(err.Error(), tc.err) {
+				t.Errorf("%q: expected error %q, got %q, %v", tc.raw, tc.err, got, err)
+			}
+			continue
+		}
+
+		if err != nil || got != tc.want {
+			t.Errorf("%q: expected %q, got %q, %v", tc.raw, tc.want, got, err)
+		}
+	}
+}
+
+func TestNewClientTrailingSlash(t *testing.T) {
+	e := newTestEngine(t)
+	rt := &recordingTransport{}
+	e.handle("/vms", func(w http.ResponseWriter, r *http.Request) {
+		writeXML(w, http.StatusOK, "<vms/>")
+	})
+
+	for _, base := range []string{e.apiURL(), e.apiURL() + "/"} {
+		c, err := NewClient(base, "user", "secret", WithHTTPClient(&http.Client{Transport: rt}))
+		if err != nil {
+			t.Fatalf("NewClient(%s): %v", base, err)
+		}
+
+		_, err = c.Get("/vms")
+		if err != nil {
+			t.Fatalf("Get with base %s: %v", base, err)
+		}
+	}
+
+	want := "/ovirt-engine/sso/oauth/token,/ovirt-engine/api/vms,/ovirt-engine/sso/oauth/token,/ovirt-engine/api/vms"
+	if got := strings.Join(rt.requests(), ","); got != want {
+		t.Fatalf("expected the requests %s, got %s", want, got)
+	}
+
+	_, err := NewClient("engine.example.com/api", "user", "secret")
+	if err == nil || err.Error() != "invalid base URL: missing scheme" {
+		t.Fatalf("expected the missing scheme to be reported, got %v", err)
+	}
+}
+
+func TestRequestURL(t *testing.T) {
+	tests := []struct {
+		base, path, want string
+	}{
+		{"https://engine/ovirt-engine/api", "vms", "https://engine/ovirt-engine/api/vms"},
+		{"https://engine/ovirt-engine/api", "/vms", "https://engine/ovirt-engine/api/vms"},
+		{"https://engine/ovirt-engine/api", "/vms/1?follow=nics", "https://engine/ovirt-engine/api/vms/1?follow=nics"},
+		{"https://engine/ovirt-engine/api", "/ovirt-engine/api/vms/1", "https://engine/ovirt-engine/api/vms/1"},
+		{"https://engine/ovirt-engine/api", "/ovirt-engine/api", "https://engine/ovirt-engine/api"},
+		{"https://engine/ovirt-engine/api", "/ovirt-engine/api/", "https://engine/ovirt-engine/api/"},
+		{"https://engine/ovirt-engine/api", "/", "https://engine/ovirt-engine/api/"},
+		{"https://engine/ovirt-engine/api", "/ovirt-engine/apis", "https://engine/ovirt-engine/api/ovirt-engine/apis"},
+		{"https://engine/ovirt-engine/api", "https://other/ovirt-engine/api/vms", "https://other/ovirt-engine/api/vms"},
+		{"https://proxy/api", "vms/1/nics", "https://proxy/api/vms/1/nics"},
+		{"https://proxy/api", "/api/vms/1", "https://proxy/api/vms/1"},
+		{"https://proxy/api", "/api", "https://proxy/api"},
+	}
+
+	for _, tc := range tests {
+		c := &Client{url: tc.base}
+		got, err := c.requestURL(tc.path)
+		if err != nil || got != tc.want {
+			t.Errorf("%s with base %s: expected %s, got %s, %v", tc.path, tc.base, tc.want, got, err)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package api
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Version is a compatibility version (e.g. 4.4) of a cluster, data center or VM
+type Version struct {
+	Major int `xml:"major"`
+	Minor int `xml:"minor"`
+}
+
+// ParseVersion parses a version in the form major.minor
+func ParseVersion(s string) (Version, error) {
+	parts := strings.Split(s, ".")
+	if len(parts) != 2 {
+		return Version{}, fmt.Errorf("invalid version %q, expected major.minor", s)
+	}
+
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return Version{}, fmt.Errorf("invalid version %q: %v", s, err)
+	}
+
+	minor, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return Version{}, fmt.Errorf("invalid version %q: %v", s, err)
+	}
+
+	return Version{Major: major, Minor: minor}, nil
+}
+
+// String returns the version in the form major.minor
+func (v Version) String() string {
+	return fmt.Sprintf("%d.%d", v.Major, v.Minor)
+}
+
+// Compare returns -1, 0 or 1 if v is lower than, equal to or greater than o
+func (v Version) Compare(o Version) int {
+	switch {
+	case v.Major != o.Major:
+		return sign(v.Major - o.Major)
+	default:
+		return sign(v.Minor - o.Minor)
+	}
+}
+
+func sign(i int) int {
+	if i < 0 {
+		return -1
+	}
+
+	if i > 0 {
+		return 1
+	}
+
+	return 0
+}
+
+func containsVersion(versions []Version, v Version) bool {
+	for _, x := range versions {
+		if x.Compare(v) == 0 {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,245 @@
+package api
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+)
+
+// VMs is a collection of VMs as returned by the API
+type VMs struct {
+	VMs []VM `xml:"vm"`
+}
+
+// VM represents a virtual machine
+type VM struct {
+	XMLName                    xml.Name         `xml:"vm"`
+	ID                         string           `xml:"id,attr,omitempty"`
+	Href                       string           `xml:"href,attr,omitempty"`
+	Name                       string           `xml:"name,omitempty"`
+	Description                string           `xml:"description,omitempty"`
+	Status                     string           `xml:"status,omitempty"`
+	Type                       string           `xml:"type,omitempty"`
+	Memory                     int64            `xml:"memory,omitempty"`
+	CPU                        *CPU             `xml:"cpu,omitempty"`
+	OS                         *OperatingSystem `xml:"os,omitempty"`
+	Cluster                    *Link            `xml:"cluster,omitempty"`
+	Host                       *Link            `xml:"host,omitempty"`
+	Template                   *Link            `xml:"template,omitempty"`
+	InstanceType               *Link            `xml:"instance_type,omitempty"`
+	Bios                       *Bios            `xml:"bios,omitempty"`
+	CustomCompatibilityVersion *Version         `xml:"custom_compatibility_version,omitempty"`
+	RNGDevice                  *RNGDevice       `xml:"rng_device,omitempty"`
+	Watchdogs                  *Watchdogs       `xml:"watchdogs,omitempty"`
+	Initialization             *Initialization  `xml:"initialization,omitempty"`
+
+	// NICs and DiskAttachments are only set if requested with GetFollow
+	NICs            *NICs            `xml:"nics,omitempty"`
+	DiskAttachments *DiskAttachments `xml:"disk_attachments,omitempty"`
+}
+
+// OperatingSystem describes the guest operating system of a VM
+type OperatingSystem struct {
+	Type string `xml:"type,omitempty"`
+}
+
+// Initialization is the guest configuration applied by cloud-init (or sysprep) on the first run
+// of the VM, or on a start with WithCloudInit
+type Initialization struct {
+	HostName          string `xml:"host_name,omitempty"`
+	UserName          string `xml:"user_name,omitempty"`
+	RootPassword      string `xml:"root_password,omitempty"`
+	AuthorizedSSHKeys string `xml:"authorized_ssh_keys,omitempty"`
+	DNSServers        string `xml:"dns_servers,omitempty"`
+	Timezone          string `xml:"timezone,omitempty"`
+	CustomScript      string `xml:"custom_script,omitempty"`
+}
+
+// VMCreateOption sets properties of a VM created with CreateVMFromTemplate
+type VMCreateOption func(*VM)
+
+// WithMemory sets the memory of the VM in bytes instead of using the memory of the template
+func WithMemory(bytes int64) VMCreateOption {
+	return func(vm *VM) {
+		vm.Memory = bytes
+	}
+}
+
+// WithCPUTopology sets the CPU topology of the VM instead of using the topology of the template
+func WithCPUTopology(sockets, cores, threads int) VMCreateOption {
+	return func(vm *VM) {
+		vm.CPU = &CPU{Topology: &CPUTopology{Sockets: sockets, Cores: cores, Threads: threads}}
+	}
+}
+
+// WithInitialization sets the cloud-init configuration of the VM
+func WithInitialization(init *Initialization) VMCreateOption {
+	return func(vm *VM) {
+		vm.Initialization = init
+	}
+}
+
+// CreateVMFromTemplate creates a VM named name in the cluster based on the template.
+// Settings not changed by opts are taken from the template.
+func (c *Client) CreateVMFromTemplate(name, clusterID, templateID string, opts ...VMCreateOption) (*VM, error) {
+	if clusterID == "" || templateID == "" {
+		return nil, errors.New("cluster and template id must not be empty")
+	}
+
+	vm := &VM{
+		Name:     name,
+		Cluster:  &Link{ID: clusterID},
+		Template: &Link{ID: templateID},
+	}
+	for _, o := range opts {
+		o(vm)
+	}
+
+	if vm.CPU != nil && vm.CPU.Topology != nil {
+		err := validateCPU(vm.CPU, vm.CPU.Topology.VCPUs())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return c.CreateVM(vm)
+}
+
+// CreateVM creates a new VM and returns the representation returned by the engine.
+// The Blank template is used if vm does not reference a template.
+//
+// If InstanceType is set the engine takes memory and CPU settings from the instance type.
+// Memory or CPU values set explicitly on vm are sent as well and override the values
+// of the instance type, in which case the engine reports the VM as customized.
+func (c *Client) CreateVM(vm *VM) (*VM, error) {
+	if vm.Name == "" {
+		return nil, errors.New("vm name must not be empty")
+	}
+
+	if vm.Cluster == nil {
+		return nil, errors.New("vm cluster must be set")
+	}
+
+	if vm.Bios != nil {
+		err := vm.Bios.Validate()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if vm.RNGDevice != nil {
+		err := vm.RNGDevice.Validate()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	body := *vm
+	if body.Template == nil {
+		body.Template = &Link{Name: "Blank"}
+	}
+
+	res := &VM{}
+	err := c.SendObject("/vms", "POST", &body, res)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// GetVM retrieves a VM by id
+func (c *Client) GetVM(id string) (*VM, error) {
+	res := &VM{}
+	err := c.GetAndParse("/vms/"+id, res)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// updateVM sends a PUT containing only the fields set in changes.
+// If nextRun is set the changes are applied when the VM is started the next time.
+func (c *Client) updateVM(id string, changes *VM, nextRun bool) (*VM, error) {
+	path := "/vms/" + id
+	if nextRun {
+		path += "?next_run=true"
+	}
+
+	res := &VM{}
+	err := c.SendObject(path, "PUT", changes, res)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// SetVMCPU sets the CPU topology and pinning of a VM. vcpus is the intended number of
+// virtual CPUs the topology has to add up to. Topology changes of a running VM are only
+// applied on its next run, so nextRun should be set for running VMs.
+func (c *Client) SetVMCPU(id string, cpu *CPU, vcpus int, nextRun bool) (*VM, error) {
+	err := validateCPU(cpu, vcpus)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.updateVM(id, &VM{CPU: cpu}, nextRun)
+}
+
+func validateCPU(cpu *CPU, vcpus int) error {
+	if cpu == nil || cpu.Topology == nil {
+		return errors.New("cpu topology must be set")
+	}
+
+	t := cpu.Topology
+	if t.Sockets <= 0 || t.Cores <= 0 || t.Threads <= 0 {
+		return errors.New("cpu sockets, cores and threads must be greater than 0")
+	}
+
+	if t.VCPUs() != vcpus {
+		return fmt.Errorf("cpu topology %d sockets * %d cores * %d threads = %d vcpus, expected %d", t.Sockets, t.Cores, t.Threads, t.VCPUs(), vcpus)
+	}
+
+	if cpu.CPUTune == nil {
+		return nil
+	}
+
+	for _, p := range cpu.CPUTune.VcpuPins {
+		if p.Vcpu < 0 || p.Vcpu >= vcpus {
+			return fmt.Errorf("vcpu pin for vcpu %d is out of range (vm has %d vcpus)", p.Vcpu, vcpus)
+		}
+
+		if p.CPUSet == "" {
+			return fmt.Errorf("vcpu pin for vcpu %d has no cpu set", p.Vcpu)
+		}
+	}
+
+	return nil
+}
+
+// SetVMCustomCompatibilityVersion pins the compatibility version of a VM, e.g. to keep it at
+// the old level during a phased cluster upgrade. The version has to be supported by the cluster
+// of the VM. The change is applied on the next run if the VM is not down.
+func (c *Client) SetVMCustomCompatibilityVersion(id string, v Version) (*VM, error) {
+	vm, err := c.GetVM(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if vm.Cluster == nil {
+		return nil, fmt.Errorf("vm %s is not assigned to a cluster", id)
+	}
+
+	cl, err := c.GetCluster(vm.Cluster.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(cl.SupportedVersions) > 0 && !containsVersion(cl.SupportedVersions, v) {
+		return nil, fmt.Errorf("version %s is not supported by cluster %s", v, cl.Name)
+	}
+
+	return c.updateVM(id, &VM{CustomCompatibilityVersion: &v}, vm.Status != "down")
+}
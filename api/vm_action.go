@@ -0,0 +1,77 @@
+package api
+
+// VMStartOption sets parameters of a start action
+type VMStartOption func(*Action)
+
+// WithPause starts the VM in paused state
+func WithPause() VMStartOption {
+	return func(a *Action) {
+		a.Pause = true
+	}
+}
+
+// WithCloudInit applies the cloud-init configuration of the VM on this start
+func WithCloudInit() VMStartOption {
+	return func(a *Action) {
+		a.UseCloudInit = true
+	}
+}
+
+// WithSysprep applies the sysprep configuration of the VM on this start (Windows guests)
+func WithSysprep() VMStartOption {
+	return func(a *Action) {
+		a.UseSysprep = true
+	}
+}
+
+// WithVolatile discards changes to the disks of the VM when it is powered off
+func WithVolatile() VMStartOption {
+	return func(a *Action) {
+		a.Volatile = true
+	}
+}
+
+// StartVM starts a VM
+func (c *Client) StartVM(id string, opts ...VMStartOption) (*Action, error) {
+	a := &Action{}
+	for _, o := range opts {
+		o(a)
+	}
+
+	return c.performAction("/vms/"+id, "start", a)
+}
+
+// RebootVM reboots the guest OS of a running VM
+func (c *Client) RebootVM(id string) (*Action, error) {
+	return c.performAction("/vms/"+id, "reboot", nil)
+}
+
+// VMStopOption sets parameters of a stop or shutdown action
+type VMStopOption func(*Action)
+
+// WithStopReason records why the VM is powered down. The engine adds the reason
+// to the audit log entry of the stop/shutdown event.
+func WithStopReason(reason string) VMStopOption {
+	return func(a *Action) {
+		a.Reason = reason
+	}
+}
+
+// StopVM powers off a VM immediately
+func (c *Client) StopVM(id string, opts ...VMStopOption) (*Action, error) {
+	return c.vmStopAction(id, "stop", opts)
+}
+
+// ShutdownVM requests the guest OS to shut down gracefully
+func (c *Client) ShutdownVM(id string, opts ...VMStopOption) (*Action, error) {
+	return c.vmStopAction(id, "shutdown", opts)
+}
+
+func (c *Client) vmStopAction(id, name string, opts []VMStopOption) (*Action, error) {
+	a := &Action{}
+	for _, o := range opts {
+		o(a)
+	}
+
+	return c.performAction("/vms/"+id, name, a)
+}
@@ -0,0 +1,96 @@
+package api
+
+import (
+	"encoding/xml"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// actionRecorder answers actions with a completed action and records the path and body of the last one
+type actionRecorder struct {
+	mu   sync.Mutex
+	path string
+	body string
+}
+
+func (ar *actionRecorder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	b, _ := io.ReadAll(r.Body)
+
+	ar.mu.Lock()
+	ar.path = r.Method + " " + strings.TrimPrefix(r.URL.Path, testAPIPath)
+	ar.body = string(b)
+	ar.mu.Unlock()
+
+	writeXML(w, http.StatusOK, `<action><status>complete</status></action>`)
+}
+
+func (ar *actionRecorder) last() (string, string) {
+	ar.mu.Lock()
+	defer ar.mu.Unlock()
+
+	return ar.path, ar.body
+}
+
+func TestStartVMWithCloudInit(t *testing.T) {
+	e := newTestEngine(t)
+	ar := &actionRecorder{}
+	e.handle("/vms/123/", ar.ServeHTTP)
+	c := e.client(t)
+
+	a, err := c.StartVM("123", WithCloudInit(), WithPause())
+	if err != nil {
+		t.Fatalf("StartVM: %v", err)
+	}
+	if a.Status != "complete" {
+		t.Fatalf("expected the parsed action, got %+v", a)
+	}
+
+	path, body := ar.last()
+	if path != "POST /vms/123/start" {
+		t.Fatalf("expected the start action, got %s", path)
+	}
+
+	sent := &Action{}
+	err = xml.Unmarshal([]byte(body), sent)
+	if err != nil {
+		t.Fatalf("invalid action %q: %v", body, err)
+	}
+	if sent.XMLName.Local != "action" || !sent.UseCloudInit || !sent.Pause || sent.UseSysprep || sent.Volatile {
+		t.Fatalf("unexpected action %s", body)
+	}
+	if !strings.Contains(body, "<use_cloud_init>true</use_cloud_init>") {
+		t.Fatalf("expected use_cloud_init in %s", body)
+	}
+}
+
+func TestVMActions(t *testing.T) {
+	e := newTestEngine(t)
+	ar := &actionRecorder{}
+	e.handle("/vms/123/", ar.ServeHTTP)
+	c := e.client(t)
+
+	tests := []struct {
+		name   string
+		action func() (*Action, error)
+		path   string
+		body   string
+	}{
+		{"stop", func() (*Action, error) { return c.StopVM("123") }, "POST /vms/123/stop", "<action></action>"},
+		{"shutdown", func() (*Action, error) { return c.ShutdownVM("123", WithStopReason("maintenance")) }, "POST /vms/123/shutdown", "<action><reason>maintenance</reason></action>"},
+		{"reboot", func() (*Action, error) { return c.RebootVM("123") }, "POST /vms/123/reboot", "<action></action>"},
+	}
+	for _, tc := range tests {
+		_, err := tc.action()
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+
+		path, body := ar.last()
+		if path != tc.path || body != tc.body {
+			t.Errorf("%s: expected %s with %s, got %s with %s", tc.name, tc.path, tc.body, path, body)
+		}
+	}
+}
@@ -0,0 +1,289 @@
+package api
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// vmReference is a reference of a VM to another entity which is exported by name
+type vmReference struct {
+	element    string
+	collection string
+	link       func(vm *VM) **Link
+}
+
+var vmReferences = []vmReference{
+	{element: "cluster", collection: "clusters", link: func(vm *VM) **Link { return &vm.Cluster }},
+	{element: "template", collection: "templates", link: func(vm *VM) **Link { return &vm.Template }},
+	{element: "instance_type", collection: "instancetypes", link: func(vm *VM) **Link { return &vm.InstanceType }},
+}
+
+// vmConfig is the portable configuration of a VM including its disks and NICs
+type vmConfig struct {
+	*VM
+	// DiskAttachments replaces the attachments of VM, which only link the disks
+	DiskAttachments []vmConfigDisk `xml:"disk_attachments>disk_attachment,omitempty"`
+}
+
+// vmConfigDisk is a disk attachment of a portable configuration with the disk inline,
+// which makes the engine create the disk when it is attached
+type vmConfigDisk struct {
+	XMLName   xml.Name `xml:"disk_attachment"`
+	Active    bool     `xml:"active,omitempty"`
+	Bootable  bool     `xml:"bootable,omitempty"`
+	Interface string   `xml:"interface,omitempty"`
+	Disk      *Disk    `xml:"disk,omitempty"`
+}
+
+// ExportVMConfig returns the configuration of a VM as portable XML. Ids, hrefs and runtime state
+// are removed and references to other entities (cluster, template, instance type, the storage
+// domains of the disks and the vNIC profiles of the NICs) are replaced by their names, so the
+// configuration can be imported in another environment. MAC addresses are not exported.
+func (c *Client) ExportVMConfig(id string) ([]byte, error) {
+	vm, err := c.GetVM(id)
+	if err != nil {
+		return nil, err
+	}
+	stripVM(vm)
+
+	for _, ref := range vmReferences {
+		l := ref.link(vm)
+		if *l == nil {
+			continue
+		}
+
+		*l, err = c.exportLink(ref.collection, *l)
+		if err != nil {
+			return nil, fmt.Errorf("could not resolve %s: %v", ref.element, err)
+		}
+	}
+
+	cfg := &vmConfig{VM: vm}
+
+	attachments, err := c.VMDiskAttachments(id)
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range attachments {
+		if a.Disk == nil {
+			continue
+		}
+
+		d, err := c.GetDisk(a.Disk.ID)
+		if err != nil {
+			return nil, err
+		}
+		stripDisk(d)
+
+		for i := range d.StorageDomains {
+			l, err := c.exportLink("storagedomains", &d.StorageDomains[i])
+			if err != nil {
+				return nil, fmt.Errorf("could not resolve storage domain of disk %s: %v", d.Alias, err)
+			}
+			d.StorageDomains[i] = *l
+		}
+
+		cfg.DiskAttachments = append(cfg.DiskAttachments, vmConfigDisk{Active: a.Active, Bootable: a.Bootable, Interface: a.Interface, Disk: d})
+	}
+
+	nics, err := c.VMNics(id)
+	if err != nil {
+		return nil, err
+	}
+	vm.NICs = nil
+	if len(nics) > 0 {
+		vm.NICs = &NICs{}
+	}
+	for _, n := range nics {
+		stripNIC(&n)
+
+		if n.VnicProfile != nil {
+			n.VnicProfile, err = c.exportLink("vnicprofiles", n.VnicProfile)
+			if err != nil {
+				return nil, fmt.Errorf("could not resolve vnic profile of nic %s: %v", n.Name, err)
+			}
+		}
+
+		vm.NICs.NICs = append(vm.NICs.NICs, n)
+	}
+
+	return xml.MarshalIndent(cfg, "", "  ")
+}
+
+// exportLink returns a link referencing the entity of l by name
+func (c *Client) exportLink(collection string, l *Link) (*Link, error) {
+	if l.Name != "" {
+		return &Link{Name: l.Name}, nil
+	}
+
+	name, err := c.entityName(collection, l.ID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", l.ID, err)
+	}
+
+	return &Link{Name: name}, nil
+}
+
+// ImportVMConfig creates a VM from a configuration exported by ExportVMConfig and adds its disks and NICs.
+// References are looked up by name in this environment, all references which could not be resolved are
+// reported in the error before anything is created. If adding a disk or NIC fails the created VM is
+// returned along with the error.
+func (c *Client) ImportVMConfig(config []byte) (*VM, error) {
+	cfg := &vmConfig{}
+	err := xml.Unmarshal(config, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("invalid vm config: %v", err)
+	}
+	if cfg.VM == nil {
+		return nil, errors.New("invalid vm config: no vm")
+	}
+
+	vm := cfg.VM
+	stripVM(vm)
+
+	unresolved := []string{}
+	resolve := func(what, collection string, l *Link) *Link {
+		if l.Name == "" {
+			unresolved = append(unresolved, what+" without name")
+			return l
+		}
+
+		id, err := c.lookupID(collection, l.Name)
+		if err != nil {
+			unresolved = append(unresolved, fmt.Sprintf("%s %q: %v", what, l.Name, err))
+			return l
+		}
+
+		return &Link{ID: id}
+	}
+
+	for _, ref := range vmReferences {
+		l := ref.link(vm)
+		if *l != nil {
+			*l = resolve(ref.element, ref.collection, *l)
+		}
+	}
+
+	for _, a := range cfg.DiskAttachments {
+		if a.Disk == nil {
+			continue
+		}
+		stripDisk(a.Disk)
+
+		for i := range a.Disk.StorageDomains {
+			a.Disk.StorageDomains[i] = *resolve("storage domain of disk "+a.Disk.Alias, "storagedomains", &a.Disk.StorageDomains[i])
+		}
+	}
+
+	nics := []NIC{}
+	if vm.NICs != nil {
+		nics = vm.NICs.NICs
+	}
+	for i := range nics {
+		stripNIC(&nics[i])
+
+		if nics[i].VnicProfile != nil {
+			nics[i].VnicProfile = resolve("vnic profile of nic "+nics[i].Name, "vnicprofiles", nics[i].VnicProfile)
+		}
+	}
+
+	if len(unresolved) > 0 {
+		return nil, errors.New("unresolved references in vm config: " + strings.Join(unresolved, ", "))
+	}
+
+	// disks and NICs can not be created along with the VM
+	vm.DiskAttachments = nil
+	vm.NICs = nil
+
+	res, err := c.CreateVM(vm)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, a := range cfg.DiskAttachments {
+		err := c.SendObject("/vms/"+res.ID+"/diskattachments", "POST", &a, &DiskAttachment{})
+		if err != nil {
+			return res, fmt.Errorf("vm %s was created, but adding disk %s failed: %w", res.ID, a.Disk.Alias, err)
+		}
+	}
+
+	for _, n := range nics {
+		err := c.SendObject("/vms/"+res.ID+"/nics", "POST", &n, &NIC{})
+		if err != nil {
+			return res, fmt.Errorf("vm %s was created, but adding nic %s failed: %w", res.ID, n.Name, err)
+		}
+	}
+
+	return res, nil
+}
+
+// lookupID returns the id of the entity with the given name
+func (c *Client) lookupID(collection, name string) (string, error) {
+	if collection != "vnicprofiles" {
+		return c.ResolveID(collection, name)
+	}
+
+	// vNIC profiles can not be searched, so all of them are listed
+	res := &namedEntities{}
+	err := c.GetAndParse("/vnicprofiles", res)
+	if err != nil {
+		return "", err
+	}
+
+	ids := []string{}
+	for _, e := range res.Items {
+		if e.Name == name {
+			ids = append(ids, e.ID)
+		}
+	}
+
+	switch len(ids) {
+	case 0:
+		return "", ErrNotFound
+	case 1:
+		return ids[0], nil
+	default:
+		return "", ErrMultipleMatches
+	}
+}
+
+// stripVM removes the id and the runtime state of a VM
+func stripVM(vm *VM) {
+	vm.ID = ""
+	vm.Href = ""
+	vm.Status = ""
+	vm.Host = nil
+}
+
+// stripDisk removes the id and the runtime state of a disk
+func stripDisk(d *Disk) {
+	d.ID = ""
+	d.Href = ""
+	d.Status = ""
+	d.ActualSize = 0
+	d.TotalSize = 0
+}
+
+// stripNIC removes the id, the MAC address (assigned from the pool of the new environment) and the VM of a NIC
+func stripNIC(n *NIC) {
+	n.ID = ""
+	n.Href = ""
+	n.MAC = nil
+	n.Network = nil
+	n.VM = nil
+}
+
+// entityName returns the name of the entity with the given id
+func (c *Client) entityName(collection, id string) (string, error) {
+	res := &struct {
+		Name string `xml:"name"`
+	}{}
+	err := c.GetAndParse("/"+collection+"/"+id, res)
+	if err != nil {
+		return "", err
+	}
+
+	return res.Name, nil
+}
@@ -0,0 +1,200 @@
+package api
+
+import (
+	"encoding/xml"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// exportTestVM exports a VM with a disk and a NIC whose references are only given by id
+func exportTestVM(t *testing.T) []byte {
+	t.Helper()
+
+	e := newTestEngine(t)
+	entity := func(path, body string) {
+		e.handle(path, func(w http.ResponseWriter, r *http.Request) {
+			writeXML(w, http.StatusOK, body)
+		})
+	}
+	entity("/vms/src", `<vm href="/ovirt-engine/api/vms/src" id="src"><name>web01</name><status>up</status><memory>4294967296</memory>`+
+		`<cluster href="/ovirt-engine/api/clusters/c1" id="c1"/><host id="h1"/><template id="t1"/></vm>`)
+	entity("/clusters/c1", `<cluster id="c1"><name>prod</name></cluster>`)
+	entity("/templates/t1", `<template id="t1"><name>centos</name></template>`)
+	entity("/vms/src/diskattachments", `<disk_attachments><disk_attachment id="d1"><active>true</active><bootable>true</bootable>`+
+		`<interface>virtio_scsi</interface><disk id="d1"/><vm id="src"/></disk_attachment></disk_attachments>`)
+	entity("/disks/d1", `<disk href="/ovirt-engine/api/disks/d1" id="d1"><alias>web01_root</alias><status>ok</status><format>cow</format>`+
+		`<provisioned_size>10737418240</provisioned_size><actual_size>1073741824</actual_size>`+
+		`<storage_domains><storage_domain id="sd1"/></storage_domains></disk>`)
+	entity("/storagedomains/sd1", `<storage_domain id="sd1"><name>data1</name></storage_domain>`)
+	entity("/vms/src/nics", `<nics><nic href="/ovirt-engine/api/vms/src/nics/n1" id="n1"><name>nic1</name><interface>virtio</interface>`+
+		`<mac><address>56:6f:00:00:00:01</address></mac><vnic_profile id="p1"/><vm id="src"/></nic></nics>`)
+	entity("/vnicprofiles/p1", `<vnic_profile id="p1"><name>ovirtmgmt</name></vnic_profile>`)
+	c := e.client(t)
+
+	config, err := c.ExportVMConfig("src")
+	if err != nil {
+		t.Fatalf("ExportVMConfig: %v", err)
+	}
+
+	return config
+}
+
+// handleNamed answers searches and listings of collection with the entities given as name and id pairs
+func (e *testEngine) handleNamed(collection, element string, entities ...string) {
+	e.handle("/"+collection, func(w http.ResponseWriter, r *http.Request) {
+		b := strings.Builder{}
+		b.WriteString("<" + collection + ">")
+		for i := 0; i+1 < len(entities); i += 2 {
+			b.WriteString(`<` + element + ` id="` + entities[i+1] + `"><name>` + entities[i] + `</name></` + element + `>`)
+		}
+		b.WriteString("</" + collection + ">")
+		writeXML(w, http.StatusOK, b.String())
+	})
+}
+
+func TestExportVMConfig(t *testing.T) {
+	config := string(exportTestVM(t))
+
+	for _, s := range []string{"id=", "href=", "<status>", "<host", "56:6f", "actual_size"} {
+		if strings.Contains(config, s) {
+			t.Fatalf("expected %q to be stripped from\n%s", s, config)
+		}
+	}
+
+	var exported struct {
+		Cluster       string `xml:"cluster>name"`
+		Template      string `xml:"template>name"`
+		Alias         string `xml:"disk_attachments>disk_attachment>disk>alias"`
+		Bootable      bool   `xml:"disk_attachments>disk_attachment>bootable"`
+		StorageDomain string `xml:"disk_attachments>disk_attachment>disk>storage_domains>storage_domain>name"`
+		NIC           string `xml:"nics>nic>name"`
+		VnicProfile   string `xml:"nics>nic>vnic_profile>name"`
+	}
+	err := xml.Unmarshal([]byte(config), &exported)
+	if err != nil {
+		t.Fatalf("invalid config %s: %v", config, err)
+	}
+
+	if exported.Cluster != "prod" || exported.Template != "centos" {
+		t.Fatalf("expected the cluster and template by name in\n%s", config)
+	}
+	if exported.Alias != "web01_root" || !exported.Bootable || exported.StorageDomain != "data1" {
+		t.Fatalf("expected the disk with its storage domain by name in\n%s", config)
+	}
+	if exported.NIC != "nic1" || exported.VnicProfile != "ovirtmgmt" {
+		t.Fatalf("expected the nic with its vnic profile by name in\n%s", config)
+	}
+}
+
+func TestImportVMConfig(t *testing.T) {
+	config := exportTestVM(t)
+
+	e := newTestEngine(t)
+	e.handleNamed("clusters", "cluster", "prod", "c2")
+	e.handleNamed("templates", "template", "centos", "t2")
+	e.handleNamed("storagedomains", "storage_domain", "data1", "sd2")
+	e.handleNamed("vnicprofiles", "vnic_profile", "ovirtmgmt", "p2", "other", "p3")
+
+	var vmBody, diskBody, nicBody []byte
+	e.handle("/vms", func(w http.ResponseWriter, r *http.Request) {
+		vmBody, _ = io.ReadAll(r.Body)
+		writeXML(w, http.StatusCreated, `<vm id="new"><name>web01</name></vm>`)
+	})
+	e.handle("/vms/new/diskattachments", func(w http.ResponseWriter, r *http.Request) {
+		diskBody, _ = io.ReadAll(r.Body)
+		writeXML(w, http.StatusCreated, `<disk_attachment id="d2"><disk id="d2"/></disk_attachment>`)
+	})
+	e.handle("/vms/new/nics", func(w http.ResponseWriter, r *http.Request) {
+		nicBody, _ = io.ReadAll(r.Body)
+		writeXML(w, http.StatusCreated, `<nic id="n2"><name>nic1</name></nic>`)
+	})
+	c := e.client(t)
+
+	vm, err := c.ImportVMConfig(config)
+	if err != nil {
+		t.Fatalf("ImportVMConfig: %v", err)
+	}
+	if vm.ID != "new" {
+		t.Fatalf("expected the created VM, got %+v", vm)
+	}
+
+	var sentVM struct {
+		ID       string `xml:"id,attr"`
+		Name     string `xml:"name"`
+		Memory   int64  `xml:"memory"`
+		Cluster  Link   `xml:"cluster"`
+		Template Link   `xml:"template"`
+	}
+	err = xml.Unmarshal(vmBody, &sentVM)
+	if err != nil {
+		t.Fatalf("invalid vm body %s: %v", vmBody, err)
+	}
+	if sentVM.ID != "" || sentVM.Name != "web01" || sentVM.Memory != 4<<30 {
+		t.Fatalf("unexpected vm body %s", vmBody)
+	}
+	if sentVM.Cluster.ID != "c2" || sentVM.Template.ID != "t2" {
+		t.Fatalf("expected the cluster and template of this environment in %s", vmBody)
+	}
+	if body := string(vmBody); strings.Contains(body, "disk_attachments") || strings.Contains(body, "<nics>") {
+		t.Fatalf("expected disks and nics to be added separately, got %s", body)
+	}
+
+	var sentDisk struct {
+		Bootable      bool   `xml:"bootable"`
+		Interface     string `xml:"interface"`
+		Alias         string `xml:"disk>alias"`
+		Size          int64  `xml:"disk>provisioned_size"`
+		StorageDomain Link   `xml:"disk>storage_domains>storage_domain"`
+	}
+	err = xml.Unmarshal(diskBody, &sentDisk)
+	if err != nil {
+		t.Fatalf("invalid disk body %s: %v", diskBody, err)
+	}
+	if !sentDisk.Bootable || sentDisk.Interface != "virtio_scsi" || sentDisk.Alias != "web01_root" || sentDisk.Size != 10<<30 {
+		t.Fatalf("unexpected disk body %s", diskBody)
+	}
+	if sentDisk.StorageDomain.ID != "sd2" || strings.Contains(string(diskBody), "d1") {
+		t.Fatalf("expected the storage domain of this environment and no disk id in %s", diskBody)
+	}
+
+	var sentNIC NIC
+	err = xml.Unmarshal(nicBody, &sentNIC)
+	if err != nil {
+		t.Fatalf("invalid nic body %s: %v", nicBody, err)
+	}
+	if sentNIC.ID != "" || sentNIC.Name != "nic1" || sentNIC.MAC != nil || sentNIC.VnicProfile == nil || sentNIC.VnicProfile.ID != "p2" {
+		t.Fatalf("unexpected nic body %s", nicBody)
+	}
+}
+
+func TestImportVMConfigUnresolved(t *testing.T) {
+	config := exportTestVM(t)
+
+	e := newTestEngine(t)
+	e.handleNamed("clusters", "cluster", "prod", "c2")
+	e.handleNamed("templates", "template", "centos", "t2")
+	e.handleNamed("storagedomains", "storage_domain")
+	e.handleNamed("vnicprofiles", "vnic_profile", "ovirtmgmt", "p2", "ovirtmgmt", "p3")
+	var created int32
+	e.handle("/vms", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&created, 1)
+		writeXML(w, http.StatusCreated, `<vm id="new"/>`)
+	})
+	c := e.client(t)
+
+	_, err := c.ImportVMConfig(config)
+	if err == nil {
+		t.Fatal("expected the unresolved references to be reported")
+	}
+	for _, s := range []string{`storage domain of disk web01_root "data1": storagedomain "data1": not found`, `vnic profile of nic nic1 "ovirtmgmt": multiple matches`} {
+		if !strings.Contains(err.Error(), s) {
+			t.Fatalf("expected %q in %v", s, err)
+		}
+	}
+	if n := atomic.LoadInt32(&created); n != 0 {
+		t.Fatalf("expected no VM to be created, got %d", n)
+	}
+}
@@ -0,0 +1,163 @@
+package api
+
+import (
+	"encoding/xml"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"testing"
+)
+
+// vmsListing is a (shortened) vms collection as returned by the engine
+const vmsListing = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<vms>
+  <vm href="/ovirt-engine/api/vms/3f3e6a4c-0c5e-4b1c-9d5d-2f6a3b1c0a11" id="3f3e6a4c-0c5e-4b1c-9d5d-2f6a3b1c0a11">
+    <actions>
+      <link href="/ovirt-engine/api/vms/3f3e6a4c-0c5e-4b1c-9d5d-2f6a3b1c0a11/start" rel="start"/>
+    </actions>
+    <name>web01</name>
+    <description>web server</description>
+    <link href="/ovirt-engine/api/vms/3f3e6a4c-0c5e-4b1c-9d5d-2f6a3b1c0a11/nics" rel="nics"/>
+    <cpu>
+      <architecture>x86_64</architecture>
+      <topology>
+        <cores>2</cores>
+        <sockets>4</sockets>
+        <threads>1</threads>
+      </topology>
+    </cpu>
+    <memory>4294967296</memory>
+    <os>
+      <type>rhel_8x64</type>
+    </os>
+    <status>up</status>
+    <type>server</type>
+    <cluster href="/ovirt-engine/api/clusters/c1" id="c1"/>
+    <host href="/ovirt-engine/api/hosts/h1" id="h1"/>
+    <template href="/ovirt-engine/api/templates/00000000-0000-0000-0000-000000000000" id="00000000-0000-0000-0000-000000000000"/>
+  </vm>
+  <vm href="/ovirt-engine/api/vms/8b1c" id="8b1c">
+    <name>db01</name>
+    <memory>8589934592</memory>
+    <status>down</status>
+    <cluster href="/ovirt-engine/api/clusters/c1" id="c1"/>
+  </vm>
+</vms>`
+
+func TestVMsListing(t *testing.T) {
+	e := newTestEngine(t)
+	e.handle("/vms", func(w http.ResponseWriter, r *http.Request) {
+		writeXML(w, http.StatusOK, vmsListing)
+	})
+	c := e.client(t)
+
+	vms := &VMs{}
+	err := c.GetAndParse("/vms", vms)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(vms.VMs) != 2 {
+		t.Fatalf("expected 2 vms, got %d", len(vms.VMs))
+	}
+
+	vm := vms.VMs[0]
+	if vm.ID != "3f3e6a4c-0c5e-4b1c-9d5d-2f6a3b1c0a11" || vm.Href != "/ovirt-engine/api/vms/"+vm.ID || vm.Name != "web01" {
+		t.Fatalf("unexpected vm %+v", vm)
+	}
+
+	if vm.Status != "up" || vm.Memory != 4<<30 || vm.OS == nil || vm.OS.Type != "rhel_8x64" {
+		t.Fatalf("unexpected vm %+v", vm)
+	}
+
+	if vm.CPU == nil || vm.CPU.Topology == nil || *vm.CPU.Topology != (CPUTopology{Sockets: 4, Cores: 2, Threads: 1}) {
+		t.Fatalf("unexpected cpu %+v", vm.CPU)
+	}
+
+	if vm.CPU.Topology.VCPUs() != 8 {
+		t.Fatalf("expected 8 vcpus, got %d", vm.CPU.Topology.VCPUs())
+	}
+
+	if vm.Cluster == nil || vm.Cluster.ID != "c1" || vm.Host == nil || vm.Host.ID != "h1" {
+		t.Fatalf("unexpected links %+v %+v", vm.Cluster, vm.Host)
+	}
+
+	if vms.VMs[1].Name != "db01" || vms.VMs[1].CPU != nil {
+		t.Fatalf("unexpected vm %+v", vms.VMs[1])
+	}
+}
+
+func TestCreateVMFromTemplate(t *testing.T) {
+	e := newTestEngine(t)
+	var body []byte
+	e.handle("/vms", func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		writeXML(w, http.StatusCreated, `<vm href="/ovirt-engine/api/vms/123" id="123"><name>web01</name><status>down</status>`+
+			`<cluster href="/ovirt-engine/api/clusters/c1" id="c1"/><template href="/ovirt-engine/api/templates/t1" id="t1"/></vm>`)
+	})
+	c := e.client(t)
+
+	vm, err := c.CreateVMFromTemplate("web01", "c1", "t1",
+		WithMemory(4<<30),
+		WithCPUTopology(2, 2, 1),
+		WithInitialization(&Initialization{HostName: "web01.example.com", AuthorizedSSHKeys: "ssh-ed25519 AAAA"}))
+	if err != nil {
+		t.Fatalf("CreateVMFromTemplate: %v", err)
+	}
+	if vm.ID != "123" || vm.Name != "web01" || vm.Status != "down" || vm.Cluster == nil || vm.Cluster.ID != "c1" {
+		t.Fatalf("expected the created VM, got %+v", vm)
+	}
+
+	// the structure of the body as expected by the engine
+	var sent struct {
+		XMLName xml.Name
+		Name    string `xml:"name"`
+		Cluster struct {
+			ID string `xml:"id,attr"`
+		} `xml:"cluster"`
+		Template struct {
+			ID string `xml:"id,attr"`
+		} `xml:"template"`
+		Memory  int64  `xml:"memory"`
+		Sockets int    `xml:"cpu>topology>sockets"`
+		Cores   int    `xml:"cpu>topology>cores"`
+		Threads int    `xml:"cpu>topology>threads"`
+		Host    string `xml:"initialization>host_name"`
+		Keys    string `xml:"initialization>authorized_ssh_keys"`
+	}
+	err = xml.Unmarshal(body, &sent)
+	if err != nil {
+		t.Fatalf("invalid body %s: %v", body, err)
+	}
+
+	if sent.XMLName.Local != "vm" || sent.Name != "web01" || sent.Cluster.ID != "c1" || sent.Template.ID != "t1" {
+		t.Fatalf("unexpected body %s", body)
+	}
+	if sent.Memory != 4<<30 || sent.Sockets != 2 || sent.Cores != 2 || sent.Threads != 1 {
+		t.Fatalf("unexpected memory or cpu in %s", body)
+	}
+	if sent.Host != "web01.example.com" || sent.Keys != "ssh-ed25519 AAAA" {
+		t.Fatalf("unexpected initialization in %s", body)
+	}
+}
+
+func TestCreateVMFromTemplateInvalid(t *testing.T) {
+	e := newTestEngine(t)
+	var requests int32
+	e.handle("/vms", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+	})
+	c := e.client(t)
+
+	_, err := c.CreateVMFromTemplate("web01", "", "t1")
+	if err == nil {
+		t.Fatal("expected an error without cluster")
+	}
+	_, err = c.CreateVMFromTemplate("", "c1", "t1")
+	if err == nil {
+		t.Fatal("expected an error without name")
+	}
+	if n := atomic.LoadInt32(&requests); n != 0 {
+		t.Fatalf("expected invalid VMs not to be sent, got %d requests", n)
+	}
+}
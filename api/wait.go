@@ -0,0 +1,49 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// pollInterval is the delay between two requests when waiting for a state change
+var pollInterval = 2 * time.Second
+
+// sleepContext waits for d to elapse. It returns ctx.Err() as soon as ctx is done,
+// so retry backoffs and polling loops stop promptly on cancellation.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// waitForStatus polls status until it returns target or ctx is done. what describes the entity in
+// the error returned if ctx is done first, which wraps ctx.Err(). It returns the last status seen.
+func waitForStatus(ctx context.Context, what, target string, status func(ctx context.Context) (string, error)) (string, error) {
+	last := ""
+	for {
+		s, err := status(ctx)
+		if ctx.Err() != nil {
+			return last, fmt.Errorf("%s did not become %s (last status: %s): %w", what, target, last, ctx.Err())
+		}
+		if err != nil {
+			return last, err
+		}
+
+		last = s
+		if last == target {
+			return last, nil
+		}
+
+		err = sleepContext(ctx, pollInterval)
+		if err != nil {
+			return last, fmt.Errorf("%s did not become %s (last status: %s): %w", what, target, last, err)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWaitForDiskStatus(t *testing.T) {
+	e := newTestEngine(t)
+	setPollInterval(t, time.Millisecond)
+	var polls int32
+	e.handle("/disks/d1", func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&polls, 1) < 3 {
+			writeXML(w, http.StatusOK, `<disk id="d1"><status>locked</status></disk>`)
+			return
+		}
+		writeXML(w, http.StatusOK, `<disk id="d1"><status>ok</status></disk>`)
+	})
+	c := e.client(t)
+
+	status, err := c.WaitForDiskStatus("d1", "ok", time.Minute)
+	if err != nil {
+		t.Fatalf("WaitForDiskStatus: %v", err)
+	}
+	if status != "ok" || atomic.LoadInt32(&polls) != 3 {
+		t.Fatalf("expected ok after 3 polls, got %s after %d", status, atomic.LoadInt32(&polls))
+	}
+}
+
+func TestWaitForDiskStatusContext(t *testing.T) {
+	e := newTestEngine(t)
+	setPollInterval(t, time.Millisecond)
+	e.handle("/disks/d1", func(w http.ResponseWriter, r *http.Request) {
+		writeXML(w, http.StatusOK, `<disk id="d1"><status>locked</status></disk>`)
+	})
+	c := e.client(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	status, err := c.WaitForDiskStatusContext(ctx, "d1", "ok")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the deadline to be exceeded, got %v", err)
+	}
+	if status != "locked" {
+		t.Fatalf("expected the last status, got %q", status)
+	}
+}
+
+func TestWaitForStorageDomainStatusContext(t *testing.T) {
+	e := newTestEngine(t)
+	setPollInterval(t, time.Millisecond)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var polls int32
+	e.handle("/datacenters/dc1/storagedomains/sd1", func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&polls, 1) == 2 {
+			cancel()
+		}
+		writeXML(w, http.StatusOK, `<storage_domain id="sd1"><status>activating</status></storage_domain>`)
+	})
+	c := e.client(t)
+
+	status, err := c.WaitForStorageDomainStatusContext(ctx, "dc1", "sd1", "active")
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the wait to be canceled, got %v", err)
+	}
+	if status != "activating" || atomic.LoadInt32(&polls) != 2 {
+		t.Fatalf("expected to stop after the second poll, got %q after %d polls", status, atomic.LoadInt32(&polls))
+	}
+}
@@ -0,0 +1,98 @@
+package api
+
+import (
+	"encoding/xml"
+	"fmt"
+)
+
+// WatchdogModel is the emulated watchdog device
+type WatchdogModel string
+
+// WatchdogAction is what the engine does when the watchdog of a VM fires
+type WatchdogAction string
+
+const (
+	// WatchdogI6300ESB is the Intel i6300ESB watchdog emulated for x86_64 VMs
+	WatchdogI6300ESB WatchdogModel = "i6300esb"
+	// WatchdogDiag288 is the watchdog emulated for s390x VMs
+	WatchdogDiag288 WatchdogModel = "diag288"
+
+	// WatchdogActionNone ignores the watchdog
+	WatchdogActionNone WatchdogAction = "none"
+	// WatchdogActionReset resets the VM
+	WatchdogActionReset WatchdogAction = "reset"
+	// WatchdogActionPoweroff powers off the VM
+	WatchdogActionPoweroff WatchdogAction = "poweroff"
+	// WatchdogActionPause pauses the VM
+	WatchdogActionPause WatchdogAction = "pause"
+	// WatchdogActionDump dumps the memory of the VM and pauses it
+	WatchdogActionDump WatchdogAction = "dump"
+)
+
+// Watchdogs is a collection of watchdogs as returned by the API
+type Watchdogs struct {
+	Watchdogs []Watchdog `xml:"watchdog"`
+}
+
+// Watchdog is a watchdog device recovering a hung VM
+type Watchdog struct {
+	XMLName xml.Name       `xml:"watchdog"`
+	ID      string         `xml:"id,attr,omitempty"`
+	Href    string         `xml:"href,attr,omitempty"`
+	Model   WatchdogModel  `xml:"model,omitempty"`
+	Action  WatchdogAction `xml:"action,omitempty"`
+}
+
+// Validate checks model and action are supported
+func (w *Watchdog) Validate() error {
+	switch w.Model {
+	case WatchdogI6300ESB, WatchdogDiag288:
+	default:
+		return fmt.Errorf("unsupported watchdog model %q", w.Model)
+	}
+
+	switch w.Action {
+	case WatchdogActionNone, WatchdogActionReset, WatchdogActionPoweroff, WatchdogActionPause:
+	case WatchdogActionDump:
+		if w.Model == WatchdogDiag288 {
+			return fmt.Errorf("watchdog action %s is not supported by model %s", w.Action, w.Model)
+		}
+	default:
+		return fmt.Errorf("unsupported watchdog action %q", w.Action)
+	}
+
+	return nil
+}
+
+// ListWatchdogs retrieves the watchdogs of a VM
+func (c *Client) ListWatchdogs(vmID string) ([]Watchdog, error) {
+	res := &Watchdogs{}
+	err := c.GetAndParse("/vms/"+vmID+"/watchdogs", res)
+	if err != nil {
+		return nil, err
+	}
+
+	return res.Watchdogs, nil
+}
+
+// AddWatchdog adds a watchdog to a VM
+func (c *Client) AddWatchdog(vmID string, w *Watchdog) (*Watchdog, error) {
+	err := w.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	res := &Watchdog{}
+	err = c.SendObject("/vms/"+vmID+"/watchdogs", "POST", w, res)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// RemoveWatchdog removes a watchdog from a VM
+func (c *Client) RemoveWatchdog(vmID, watchdogID string) error {
+	_, err := c.Delete("/vms/" + vmID + "/watchdogs/" + watchdogID)
+	return err
+}
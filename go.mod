@@ -1,2 +1,3 @@
 module github.com/msdnna/ovirt_api
 
+go 1.20
@@ -18,8 +18,8 @@ func main() {
 		log.Fatal(err)
 	}
 
-	vms := &VMs{}
-	err = c.SendAndParse("/vms", "GET", vms, nil)
+	vms := &api.VMs{}
+	err = c.GetAndParse("/vms", vms)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -28,11 +28,3 @@ func main() {
 		log.Println(vm.Name)
 	}
 }
-
-type VMs struct {
-	VMs []VM `xml:"vm"`
-}
-
-type VM struct {
-	Name string `xml:"name"`
-}